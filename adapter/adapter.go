@@ -26,29 +26,35 @@ import (
 	"github.com/dvaumoron/indentlang/template"
 )
 
-func LoadTemplates(templatesPath string) (map[string]template.Template, error) {
-	templatesPath, err := filepath.Abs(templatesPath)
+// Only the templates in templatesPath are loaded, otherRoots are searched in order
+// by the Import directive, a "name=path" declaration allow Import "@name/file".
+func LoadTemplates(templatesPath string, otherRoots ...string) (template.Set, error) {
+	searchPath, err := builtins.MakeSearchPath(templatesPath, otherRoots...)
 	if err != nil {
-		return nil, err
+		return template.Set{}, err
 	}
-	templatesPath = builtins.CheckPath(templatesPath)
+	templatesPath = searchPath.Roots[0]
 
-	importDirective := builtins.MakeImportDirective(templatesPath)
+	importDirective := builtins.MakeSearchPathImportDirective(searchPath)
 
-	templates := map[string]template.Template{}
+	templates := template.MakeSet()
 	inSize := len(templatesPath)
 	err = filepath.WalkDir(templatesPath, func(path string, d fs.DirEntry, err error) error {
 		if err == nil && !d.IsDir() {
 			name := path[inSize:]
 			if end := len(name) - builtins.DefaultExtLen; name[end:] == builtins.DefaultExt {
-				templates[name[:end]] = template.ParseWithImport(importDirective, name)
+				tmpl, err := template.ParseWithImport(importDirective, name)
+				if err != nil {
+					return err
+				}
+				templates.Add(name[:end], tmpl)
 			}
 		}
 		return err
 	})
 
 	if err != nil {
-		return nil, err
+		return template.Set{}, err
 	}
 	return templates, nil
 }
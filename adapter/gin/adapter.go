@@ -33,13 +33,14 @@ import (
 
 // match Render interface from gin.
 type indentlangHTML struct {
-	Template template.Template
-	Data     any
+	Templates template.Set
+	Name      string
+	Data      any
 }
 
 func (r indentlangHTML) Render(w http.ResponseWriter) error {
 	r.WriteContentType(w)
-	return r.Template.Execute(w, r.Data)
+	return r.Templates.ExecuteTemplate(w, r.Name, r.Data)
 }
 
 const contentTypeName = "Content-Type"
@@ -56,19 +57,20 @@ func (r indentlangHTML) WriteContentType(w http.ResponseWriter) {
 
 // match HTMLRender interface from gin.
 type indentlangHTMLRender struct {
-	Templates map[string]template.Template
+	Templates template.Set
 }
 
 func (r indentlangHTMLRender) Instance(name string, data any) render.Render {
 	return indentlangHTML{
-		Template: r.Templates[name],
-		Data:     data,
+		Templates: r.Templates,
+		Name:      name,
+		Data:      data,
 	}
 }
 
 // Use this method to init the HTMLRender in a gin Engine.
-func LoadTemplatesAsRender(templatesPath string) (render.HTMLRender, error) {
-	templates, err := adapter.LoadTemplates(templatesPath)
+func LoadTemplatesAsRender(templatesPath string, otherRoots ...string) (render.HTMLRender, error) {
+	templates, err := adapter.LoadTemplates(templatesPath, otherRoots...)
 	if err != nil {
 		return nil, err
 	}
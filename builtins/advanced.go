@@ -91,7 +91,7 @@ func delForm(env types.Environment, itArgs types.Iterator) types.Object {
 	arg0, _ := itArgs.Next()
 	arg1, ok := itArgs.Next()
 	if ok {
-		dict, _ := arg0.Eval(env).(types.Environment)
+		dict, _ := arg0.Eval(env).(types.Deletable)
 		if dict != nil {
 			dict.Delete(arg1.Eval(env))
 		}
@@ -28,130 +28,125 @@ const MainName = "Main"
 var Builtins = initBuitins()
 
 func initBuitins() types.BaseEnvironment {
-	elementHtml := createXmlTag("html")
+	elementHtml := createHtmlTag("html")
 
 	base := types.MakeBaseEnvironment()
-	// special case in order to create Main,
+	// special cases in order to create Main,
 	// which will be called by the Execute method of the Template struct
-	base.StoreStr("html", types.MakeNativeAppliable(func(env types.Environment, itArgs types.Iterator) types.Object {
-		// avoid loss in multiple call case
-		savedArgs := types.NewList().AddAll(itArgs)
-		env.StoreStr(MainName, types.MakeNativeAppliable(func(callEnv types.Environment, emptyArgs types.Iterator) types.Object {
-			return elementHtml.Apply(callEnv, savedArgs)
-		}))
-		return types.None
-	}))
+	base.StoreStr("Document", makeMainForm(documentMain))
+	// html is a shortcut for a Document with an html doctype and root element
+	base.StoreStr("html", makeMainForm(makeHtmlMain(elementHtml)))
 	// all other not deprecated html element
-	addXmlTag(base, "a")
-	addXmlTag(base, "abbr")
-	addXmlTag(base, "address")
-	addXmlTag(base, "area")
-	addXmlTag(base, "article")
-	addXmlTag(base, "aside")
-	addXmlTag(base, "audio")
-	addXmlTag(base, "b")
-	addXmlTag(base, "base")
-	addXmlTag(base, "bdi")
-	addXmlTag(base, "bdo")
-	addXmlTag(base, "blockquote")
-	addXmlTag(base, "body")
-	addXmlTag(base, "br")
-	addXmlTag(base, "button")
-	addXmlTag(base, "canvas")
-	addXmlTag(base, "caption")
-	addXmlTag(base, "cite")
-	addXmlTag(base, "code")
-	addXmlTag(base, "col")
-	addXmlTag(base, "colgroup")
-	addXmlTag(base, "data")
-	addXmlTag(base, "datalist")
-	addXmlTag(base, "dd")
-	addXmlTag(base, "del")
-	addXmlTag(base, "details")
-	addXmlTag(base, "dfn")
-	addXmlTag(base, "dialog")
-	addXmlTag(base, "div")
-	addXmlTag(base, "dl")
-	addXmlTag(base, "dt")
-	addXmlTag(base, "em")
-	addXmlTag(base, "embed")
-	addXmlTag(base, "fieldset")
-	addXmlTag(base, "figcaption")
-	addXmlTag(base, "figure")
-	addXmlTag(base, "footer")
-	addXmlTag(base, "form")
-	addXmlTag(base, "h1")
-	addXmlTag(base, "h2")
-	addXmlTag(base, "h3")
-	addXmlTag(base, "h4")
-	addXmlTag(base, "h5")
-	addXmlTag(base, "h6")
-	addXmlTag(base, "head")
-	addXmlTag(base, "header")
-	addXmlTag(base, "hgroup")
-	addXmlTag(base, "hr")
-	addXmlTag(base, "i")
-	addXmlTag(base, "iframe")
-	addXmlTag(base, "img")
-	addXmlTag(base, "input")
-	addXmlTag(base, "ins")
-	addXmlTag(base, "kbd")
-	addXmlTag(base, "label")
-	addXmlTag(base, "legend")
-	addXmlTag(base, "li")
-	addXmlTag(base, "link")
-	addXmlTag(base, "main")
-	addXmlTag(base, "map")
-	addXmlTag(base, "mark")
-	addXmlTag(base, "menu")
-	addXmlTag(base, "meta")
-	addXmlTag(base, "meter")
-	addXmlTag(base, "nav")
-	addXmlTag(base, "noscript")
-	addXmlTag(base, "object")
-	addXmlTag(base, "ol")
-	addXmlTag(base, "optgroup")
-	addXmlTag(base, "option")
-	addXmlTag(base, "output")
-	addXmlTag(base, "p")
-	addXmlTag(base, "picture")
-	addXmlTag(base, "pre")
-	addXmlTag(base, "progress")
-	addXmlTag(base, "q")
-	addXmlTag(base, "rp")
-	addXmlTag(base, "rt")
-	addXmlTag(base, "ruby")
-	addXmlTag(base, "s")
-	addXmlTag(base, "samp")
-	addXmlTag(base, "script")
-	addXmlTag(base, "section")
-	addXmlTag(base, "select")
-	addXmlTag(base, "slot")
-	addXmlTag(base, "small")
-	addXmlTag(base, "source")
-	addXmlTag(base, "span")
-	addXmlTag(base, "strong")
-	addXmlTag(base, "style")
-	addXmlTag(base, "sub")
-	addXmlTag(base, "summary")
-	addXmlTag(base, "sup")
-	addXmlTag(base, "table")
-	addXmlTag(base, "tbody")
-	addXmlTag(base, "td")
-	addXmlTag(base, "template")
-	addXmlTag(base, "textarea")
-	addXmlTag(base, "tfoot")
-	addXmlTag(base, "th")
-	addXmlTag(base, "thead")
-	addXmlTag(base, "time")
-	addXmlTag(base, "title")
-	addXmlTag(base, "tr")
-	addXmlTag(base, "track")
-	addXmlTag(base, "u")
-	addXmlTag(base, "ul")
-	addXmlTag(base, "var")
-	addXmlTag(base, "video")
-	addXmlTag(base, "wbr")
+	addHtmlTag(base, "a")
+	addHtmlTag(base, "abbr")
+	addHtmlTag(base, "address")
+	addHtmlTag(base, "area")
+	addHtmlTag(base, "article")
+	addHtmlTag(base, "aside")
+	addHtmlTag(base, "audio")
+	addHtmlTag(base, "b")
+	addHtmlTag(base, "base")
+	addHtmlTag(base, "bdi")
+	addHtmlTag(base, "bdo")
+	addHtmlTag(base, "blockquote")
+	addHtmlTag(base, "body")
+	addHtmlTag(base, "br")
+	addHtmlTag(base, "button")
+	addHtmlTag(base, "canvas")
+	addHtmlTag(base, "caption")
+	addHtmlTag(base, "cite")
+	addHtmlTag(base, "code")
+	addHtmlTag(base, "col")
+	addHtmlTag(base, "colgroup")
+	addHtmlTag(base, "data")
+	addHtmlTag(base, "datalist")
+	addHtmlTag(base, "dd")
+	addHtmlTag(base, "del")
+	addHtmlTag(base, "details")
+	addHtmlTag(base, "dfn")
+	addHtmlTag(base, "dialog")
+	addHtmlTag(base, "div")
+	addHtmlTag(base, "dl")
+	addHtmlTag(base, "dt")
+	addHtmlTag(base, "em")
+	addHtmlTag(base, "embed")
+	addHtmlTag(base, "fieldset")
+	addHtmlTag(base, "figcaption")
+	addHtmlTag(base, "figure")
+	addHtmlTag(base, "footer")
+	addHtmlTag(base, "form")
+	addHtmlTag(base, "h1")
+	addHtmlTag(base, "h2")
+	addHtmlTag(base, "h3")
+	addHtmlTag(base, "h4")
+	addHtmlTag(base, "h5")
+	addHtmlTag(base, "h6")
+	addHtmlTag(base, "head")
+	addHtmlTag(base, "header")
+	addHtmlTag(base, "hgroup")
+	addHtmlTag(base, "hr")
+	addHtmlTag(base, "i")
+	addHtmlTag(base, "iframe")
+	addHtmlTag(base, "img")
+	addHtmlTag(base, "input")
+	addHtmlTag(base, "ins")
+	addHtmlTag(base, "kbd")
+	addHtmlTag(base, "label")
+	addHtmlTag(base, "legend")
+	addHtmlTag(base, "li")
+	addHtmlTag(base, "link")
+	addHtmlTag(base, "main")
+	addHtmlTag(base, "map")
+	addHtmlTag(base, "mark")
+	addHtmlTag(base, "menu")
+	addHtmlTag(base, "meta")
+	addHtmlTag(base, "meter")
+	addHtmlTag(base, "nav")
+	addHtmlTag(base, "noscript")
+	addHtmlTag(base, "object")
+	addHtmlTag(base, "ol")
+	addHtmlTag(base, "optgroup")
+	addHtmlTag(base, "option")
+	addHtmlTag(base, "output")
+	addHtmlTag(base, "p")
+	addHtmlTag(base, "picture")
+	addHtmlTag(base, "pre")
+	addHtmlTag(base, "progress")
+	addHtmlTag(base, "q")
+	addHtmlTag(base, "rp")
+	addHtmlTag(base, "rt")
+	addHtmlTag(base, "ruby")
+	addHtmlTag(base, "s")
+	addHtmlTag(base, "samp")
+	addHtmlTag(base, "script")
+	addHtmlTag(base, "section")
+	addHtmlTag(base, "select")
+	addHtmlTag(base, "slot")
+	addHtmlTag(base, "small")
+	addHtmlTag(base, "source")
+	addHtmlTag(base, "span")
+	addHtmlTag(base, "strong")
+	addHtmlTag(base, "style")
+	addHtmlTag(base, "sub")
+	addHtmlTag(base, "summary")
+	addHtmlTag(base, "sup")
+	addHtmlTag(base, "table")
+	addHtmlTag(base, "tbody")
+	addHtmlTag(base, "td")
+	addHtmlTag(base, "template")
+	addHtmlTag(base, "textarea")
+	addHtmlTag(base, "tfoot")
+	addHtmlTag(base, "th")
+	addHtmlTag(base, "thead")
+	addHtmlTag(base, "time")
+	addHtmlTag(base, "title")
+	addHtmlTag(base, "tr")
+	addHtmlTag(base, "track")
+	addHtmlTag(base, "u")
+	addHtmlTag(base, "ul")
+	addHtmlTag(base, "var")
+	addHtmlTag(base, "video")
+	addHtmlTag(base, "wbr")
 
 	// start of the "true" language features
 	// *Form indicate a special form
@@ -175,12 +170,43 @@ func initBuitins() types.BaseEnvironment {
 	base.StoreStr("Boolean", types.MakeNativeAppliable(boolConvFunc))
 	base.StoreStr("Integer", types.MakeNativeAppliable(intConvFunc))
 	base.StoreStr("Float", types.MakeNativeAppliable(floatConvFunc))
+	base.StoreStr("Decimal", types.MakeNativeAppliable(decimalConvFunc))
 	base.StoreStr("String", types.MakeNativeAppliable(stringConvFunc))
 	base.StoreStr(string(parser.ListId), types.MakeNativeAppliable(listFunc))
 	base.StoreStr("Dict", types.MakeNativeAppliable(dictFunc))
+
+	// time management
+	base.StoreStr("Time", types.MakeNativeAppliable(timeConvFunc))
+	base.StoreStr("Duration", types.MakeNativeAppliable(durationConvFunc))
+	base.StoreStr("Now", types.MakeNativeAppliable(nowFunc))
+	base.StoreStr("FormatTime", types.MakeNativeAppliable(formatTimeFunc))
+	base.StoreStr("InZone", types.MakeNativeAppliable(inZoneFunc))
+	base.StoreStr("AddDuration", types.MakeNativeAppliable(addDurationFunc))
+	base.StoreStr("TimeDiff", types.MakeNativeAppliable(timeDiffFunc))
+	base.StoreStr("RelativeTime", types.MakeNativeAppliable(relativeTimeFunc))
+
+	// Dict helpers
+	base.StoreStr("Keys", types.MakeNativeAppliable(keysFunc))
+	base.StoreStr("Values", types.MakeNativeAppliable(valuesFunc))
+	base.StoreStr("Items", types.MakeNativeAppliable(itemsFunc))
+	base.StoreStr("Has", types.MakeNativeAppliable(hasFunc))
+	base.StoreStr("Get", types.MakeNativeAppliable(getFunc))
+	base.StoreStr("Merge", types.MakeNativeAppliable(mergeFunc))
+	base.StoreStr("DeepMerge", types.MakeNativeAppliable(deepMergeFunc))
 	// allowing other XMLs beyond HTML
 	base.StoreStr("XmlTag", types.MakeNativeAppliable(xmlTagFunc))
 
+	base.StoreStr("XmlNamespace", types.MakeNativeAppliable(xmlNamespaceFunc))
+	base.StoreStr("Classes", types.MakeNativeAppliable(classesFunc))
+
+	// document prologue and other markups
+	base.StoreStr("HtmlDoctype", types.MakeNativeAppliable(htmlDoctypeForm))
+	base.StoreStr("Doctype", types.MakeNativeAppliable(doctypeFunc))
+	base.StoreStr("Prologue", types.MakeNativeAppliable(prologueFunc))
+	base.StoreStr("ProcessingInstruction", types.MakeNativeAppliable(processingFunc))
+	base.StoreStr("CData", types.MakeNativeAppliable(cdataFunc))
+	base.StoreStr("Comment", types.MakeNativeAppliable(commentFunc))
+
 	// logic
 	base.StoreStr("Not", types.MakeNativeAppliable(notFunc))
 	base.StoreStr("And", types.MakeNativeAppliable(andFunc))
@@ -191,6 +217,7 @@ func initBuitins() types.BaseEnvironment {
 	base.StoreStr(">=", types.MakeNativeAppliable(greaterEqualFunc))
 	base.StoreStr("<", types.MakeNativeAppliable(lessThanFunc))
 	base.StoreStr("<=", types.MakeNativeAppliable(lessEqualFunc))
+	base.StoreStr("Sort", types.MakeNativeAppliable(sortFunc))
 
 	// advanced looping
 	base.StoreStr("Range", types.MakeNativeAppliable(rangeFunc))
@@ -209,6 +236,7 @@ func initBuitins() types.BaseEnvironment {
 	base.StoreStr(divideName, types.MakeNativeAppliable(divideFunc))
 	base.StoreStr(floorDivideName, types.MakeNativeAppliable(floorDivideFunc))
 	base.StoreStr(remainderName, types.MakeNativeAppliable(remainderFunc))
+	base.StoreStr("Round", types.MakeNativeAppliable(roundFunc))
 	base.StoreStr("+=", types.MakeNativeAppliable(sumSetForm))
 	base.StoreStr("-=", types.MakeNativeAppliable(minusSetForm))
 	base.StoreStr("*=", types.MakeNativeAppliable(productSetForm))
@@ -229,6 +257,7 @@ func initBuitins() types.BaseEnvironment {
 
 	// escape functions
 	base.StoreStr("EscapeHtml", types.MakeNativeAppliable(escapeHtmlFunc))
+	base.StoreStr("EscapeXml", types.MakeNativeAppliable(escapeXmlFunc))
 	base.StoreStr("EscapeQuery", types.MakeNativeAppliable(escapeQueryFunc))
 	base.StoreStr("EscapePath", types.MakeNativeAppliable(escapePathFunc))
 
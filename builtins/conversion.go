@@ -49,6 +49,8 @@ func extractBoolean(o types.Object) bool {
 		return casted != 0
 	case types.Float:
 		return casted != 0
+	case types.Decimal:
+		return casted.Sign() != 0
 	case types.Sizable:
 		return casted.Size() != 0
 	}
@@ -70,6 +72,9 @@ func extractInteger(o types.Object) int64 {
 		return int64(casted)
 	case types.Float:
 		return int64(casted)
+	case types.Decimal:
+		res, _ := casted.Int64()
+		return res
 	case types.String:
 		temp, err := strconv.ParseInt(string(casted), 10, 64)
 		if err == nil {
@@ -94,6 +99,8 @@ func extractFloat(o types.Object) float64 {
 		return float64(casted)
 	case types.Float:
 		return float64(casted)
+	case types.Decimal:
+		return casted.Float64()
 	case types.String:
 		temp, err := strconv.ParseFloat(string(casted), 64)
 		if err == nil {
@@ -103,6 +110,29 @@ func extractFloat(o types.Object) float64 {
 	return 0
 }
 
+// a String is parsed (like "12.50"), a Float use its shortest representation
+func decimalConvFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg, _ := itArgs.Next()
+	if res, ok := extractDecimal(arg.Eval(env)); ok {
+		return res
+	}
+	return types.None
+}
+
+func extractDecimal(o types.Object) (types.Decimal, bool) {
+	switch casted := o.(type) {
+	case types.Integer:
+		return types.DecimalFromInt(int64(casted)), true
+	case types.Float:
+		return types.DecimalFromFloat(float64(casted))
+	case types.Decimal:
+		return casted, true
+	case types.String:
+		return types.ParseDecimal(strings.TrimSpace(string(casted)))
+	}
+	return types.Decimal{}, false
+}
+
 func stringConvFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	arg, _ := itArgs.Next()
 	return types.String(extractString(arg.Eval(env)))
@@ -119,6 +149,8 @@ func extractString(o types.Object) string {
 		return strconv.FormatInt(int64(casted), 10)
 	case types.Float:
 		return strconv.FormatFloat(float64(casted), 'g', -1, 64)
+	case types.Decimal:
+		return casted.String()
 	case types.String:
 		return string(casted)
 	case types.Iterable:
@@ -146,7 +178,7 @@ func listFunc(env types.Environment, itArgs types.Iterator) types.Object {
 }
 
 func dictFunc(env types.Environment, itArgs types.Iterator) types.Object {
-	res := types.MakeBaseEnvironment()
+	res := types.NewDict()
 	types.ForEach(itArgs, func(arg types.Object) bool {
 		it, ok := arg.Eval(env).(types.Iterable)
 		if !ok {
@@ -0,0 +1,161 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package builtins
+
+import "github.com/dvaumoron/indentlang/types"
+
+// a Dict is returned as is, other pairs (like a Go map) are copied in a new Dict,
+// nil when object is not Iterable
+func toDict(object types.Object) *types.Dict {
+	switch casted := object.(type) {
+	case *types.Dict:
+		return casted
+	case types.Iterable:
+		res := types.NewDict()
+		types.ForEach(casted, func(pair types.Object) bool {
+			if key, value, ok := extractPair(pair); ok {
+				if _, isInt := key.(types.Integer); !isInt {
+					key = types.String(extractString(key))
+				}
+				res.Store(key, value)
+			}
+			return true
+		})
+		return res
+	}
+	return nil
+}
+
+func evalDictArg(env types.Environment, itArgs types.Iterator) *types.Dict {
+	arg, _ := itArgs.Next()
+	return toDict(arg.Eval(env))
+}
+
+func keysFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	res := types.NewList()
+	if dict := evalDictArg(env, itArgs); dict != nil {
+		for _, key := range dict.Keys() {
+			res.Add(key)
+		}
+	}
+	return res
+}
+
+func valuesFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	res := types.NewList()
+	if dict := evalDictArg(env, itArgs); dict != nil {
+		for _, key := range dict.Keys() {
+			res.Add(dict.Load(key))
+		}
+	}
+	return res
+}
+
+// give a List of (key value) pairs
+func itemsFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	res := types.NewList()
+	if dict := evalDictArg(env, itArgs); dict != nil {
+		res.AddAll(dict)
+	}
+	return res
+}
+
+func hasFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	arg1, _ := itArgs.Next()
+	switch casted := arg0.Eval(env).(type) {
+	case *types.Dict:
+		return types.Boolean(casted.Has(arg1.Eval(env)))
+	case types.StringLoadable:
+		if key, ok := arg1.Eval(env).(types.String); ok {
+			_, ok = casted.LoadStr(string(key))
+			return types.Boolean(ok)
+		}
+	}
+	return types.Boolean(false)
+}
+
+// Get dict key default, the default value is None when missing
+func getFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	arg1, _ := itArgs.Next()
+	arg2, ok := itArgs.Next()
+	var defaultValue types.Object = types.None
+	if ok {
+		defaultValue = arg2.Eval(env)
+	}
+
+	key := arg1.Eval(env)
+	switch casted := arg0.Eval(env).(type) {
+	case *types.Dict:
+		if res, ok := casted.Get(key); ok {
+			return res
+		}
+	case types.StringLoadable:
+		if str, ok := key.(types.String); ok {
+			if res, ok := casted.LoadStr(string(str)); ok {
+				return res
+			}
+		} else if loadable, ok := casted.(types.Loadable); ok {
+			// like a Go map with non string keys
+			if res := loadable.Load(key); res != types.None {
+				return res
+			}
+		}
+	}
+	return defaultValue
+}
+
+// give a new Dict, for a key in several arguments the last value wins
+func mergeFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	return merge(env, itArgs, false)
+}
+
+// like Merge but the Dict values of a same key are merged recursively
+func deepMergeFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	return merge(env, itArgs, true)
+}
+
+func merge(env types.Environment, itArgs types.Iterator, deep bool) types.Object {
+	res := types.NewDict()
+	types.ForEach(itArgs, func(arg types.Object) bool {
+		if dict := toDict(arg.Eval(env)); dict != nil {
+			mergeInto(res, dict, deep)
+		}
+		return true
+	})
+	return res
+}
+
+func mergeInto(res *types.Dict, dict *types.Dict, deep bool) {
+	for _, key := range dict.Keys() {
+		value := dict.Load(key)
+		if deep {
+			previousDict, ok0 := res.Load(key).(*types.Dict)
+			valueDict, ok1 := value.(*types.Dict)
+			if ok0 && ok1 {
+				// copy to not modify the arguments
+				merged := previousDict.Copy()
+				mergeInto(merged, valueDict, true)
+				value = merged
+			}
+		}
+		res.Store(key, value)
+	}
+}
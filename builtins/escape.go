@@ -21,6 +21,7 @@ package builtins
 import (
 	"html"
 	"net/url"
+	"strings"
 
 	"github.com/dvaumoron/indentlang/types"
 )
@@ -29,6 +30,15 @@ func escapeHtmlFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	return escapingFunc(env, itArgs, html.EscapeString)
 }
 
+// escape the five predefined entities of XML
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;", "'", "&apos;",
+)
+
+func escapeXmlFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	return escapingFunc(env, itArgs, xmlEscaper.Replace)
+}
+
 func escapeQueryFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	return escapingFunc(env, itArgs, url.QueryEscape)
 }
@@ -0,0 +1,64 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package builtins
+
+import "github.com/dvaumoron/indentlang/types"
+
+// reserved root name, Import "@builtin/svg" give the SVG elements
+const builtinRootName = "builtin"
+
+const builtinModulePrefix = string(rootPrefix) + builtinRootName + "/"
+
+var builtinModules = map[string]types.BaseEnvironment{
+	builtinModulePrefix + "svg":    initForeignModule(svgElements),
+	builtinModulePrefix + "mathml": initForeignModule(mathmlElements),
+}
+
+// foreign elements are self-closed when they have no children (like in XML),
+// the names shared with HTML (a, script, style, title) keep their HTML builders.
+func initForeignModule(names []string) types.BaseEnvironment {
+	module := types.MakeBaseEnvironment()
+	for _, name := range names {
+		module.StoreStr(name, createXmlTag(name))
+	}
+	return module
+}
+
+var svgElements = []string{
+	"animate", "animateMotion", "animateTransform", "circle", "clipPath",
+	"defs", "desc", "ellipse", "feBlend", "feColorMatrix",
+	"feComponentTransfer", "feComposite", "feConvolveMatrix",
+	"feDiffuseLighting", "feDisplacementMap", "feDistantLight",
+	"feDropShadow", "feFlood", "feFuncA", "feFuncB", "feFuncG", "feFuncR",
+	"feGaussianBlur", "feImage", "feMerge", "feMergeNode", "feMorphology",
+	"feOffset", "fePointLight", "feSpecularLighting", "feSpotLight",
+	"feTile", "feTurbulence", "filter", "foreignObject", "g", "image",
+	"line", "linearGradient", "marker", "mask", "metadata", "mpath", "path",
+	"pattern", "polygon", "polyline", "radialGradient", "rect", "set",
+	"stop", "svg", "switch", "symbol", "text", "textPath", "tspan", "use",
+	"view",
+}
+
+var mathmlElements = []string{
+	"annotation", "annotation-xml", "maction", "math", "menclose", "merror",
+	"mfrac", "mi", "mlabeledtr", "mmultiscripts", "mn", "mo", "mover",
+	"mpadded", "mphantom", "mprescripts", "mroot", "mrow", "ms", "mspace",
+	"msqrt", "mstyle", "msub", "msubsup", "msup", "mtable", "mtd", "mtext",
+	"mtr", "munder", "munderover", "none", "semantics",
+}
@@ -24,6 +24,8 @@ const returnName = "Return"
 
 // user can not directly use this kind of id (# start comment)
 const hiddenReturnName = "#return"
+const hiddenFuncsName = "#funcs"
+const hiddenCopiesName = "#copies"
 
 type returnMarker struct{}
 
@@ -133,7 +135,7 @@ type userAppliable struct {
 }
 
 func (u userAppliable) Apply(callEnv types.Environment, args types.Iterable) (res types.Object) {
-	local := u.initEnv(u.creationEnv)
+	local := u.initEnv(executionEnv(u.creationEnv, callEnv))
 	itArgs := args.Iter()
 	defer itArgs.Close()
 	u.retrieveArgs(callEnv, local, itArgs)
@@ -152,7 +154,11 @@ func (u userAppliable) manageReturn(callEnv types.Environment, local types.Envir
 }
 
 func (u userAppliable) ApplyWithData(data any, callEnv types.Environment, args types.Iterable) (res types.Object) {
-	local := u.initEnv(types.MakeMergeEnvironment(types.MakeDataEnvironment(data, u.creationEnv), callEnv))
+	creationEnv := u.creationEnv
+	if _, copies, ok := loadExecution(callEnv); ok {
+		creationEnv = copyingEnvironment{Environment: creationEnv, copies: copies}
+	}
+	local := u.initEnv(types.MakeMergeEnvironment(types.MakeDataEnvironment(data, creationEnv), callEnv))
 	itArgs := args.Iter()
 	defer itArgs.Close()
 	u.retrieveArgs(callEnv, local, itArgs)
@@ -162,13 +168,74 @@ func (u userAppliable) ApplyWithData(data any, callEnv types.Environment, args t
 }
 
 func (u userAppliable) defaultApply(callEnv types.Environment, itArgs types.Iterator) (res types.Object) {
-	local := u.initEnv(u.creationEnv)
+	local := u.initEnv(executionEnv(u.creationEnv, callEnv))
 	u.defaultRetrieveArgs(callEnv, local, itArgs)
 	defer u.manageReturn(callEnv, local, &res)
 	evalBody(u.body, local)
 	return types.None
 }
 
+// the returned environment is used for an execution of a template defined in env, the objects of funcs
+// are visible from the template body and from any Func or Macro (even imported) called during the execution,
+// they can not hide the builtins or the definitions.
+func MakeExecutionEnvironment(env types.Environment, funcs types.Environment) types.LocalEnvironment {
+	copies := executionCopies{copies: map[types.Object]types.Object{}}
+	scope := types.MakeLocalEnvironment(funcs)
+	scope.StoreStr(hiddenFuncsName, scope)
+	scope.StoreStr(hiddenCopiesName, copies)
+	return types.MakeLocalEnvironment(types.MakeMergeEnvironment(copyingEnvironment{Environment: env, copies: copies}, scope))
+}
+
+// the objects used in place of the shared ones (see types.Share) during an execution
+type executionCopies struct {
+	types.NoneType
+	copies map[types.Object]types.Object
+}
+
+// give the funcs and the copies of the current execution (see MakeExecutionEnvironment)
+func loadExecution(callEnv types.Environment) (types.Environment, executionCopies, bool) {
+	funcs, _ := callEnv.LoadStr(hiddenFuncsName)
+	funcsEnv, ok := funcs.(types.Environment)
+	if !ok {
+		return nil, executionCopies{}, false
+	}
+	copies, _ := funcsEnv.LoadStr(hiddenCopiesName)
+	casted, ok := copies.(executionCopies)
+	return funcsEnv, casted, ok
+}
+
+// add the funcs of the current execution after creationEnv, whose shared elements are copied
+func executionEnv(creationEnv types.Environment, callEnv types.Environment) types.Environment {
+	if funcsEnv, copies, ok := loadExecution(callEnv); ok {
+		return types.MakeMergeEnvironment(copyingEnvironment{Environment: creationEnv, copies: copies}, funcsEnv)
+	}
+	return creationEnv
+}
+
+// give a copy of the shared elements (and of the lists and Dicts containing one) which is kept for the execution,
+// so a modification does not change the other executions.
+type copyingEnvironment struct {
+	types.Environment
+	copies executionCopies
+}
+
+func (c copyingEnvironment) LoadStr(key string) (types.Object, bool) {
+	res, ok := c.Environment.LoadStr(key)
+	if !types.IsShared(res) {
+		return res, ok
+	}
+	copied, done := c.copies.copies[res]
+	if !done {
+		copied = types.CopyTree(res)
+		c.copies.copies[res] = copied
+	}
+	return copied, true
+}
+
+func (c copyingEnvironment) Load(key types.Object) types.Object {
+	return types.Load(c, key)
+}
+
 func evalBody(body *types.List, local types.Environment) {
 	types.ForEach(body, func(line types.Object) bool {
 		line.Eval(local)
@@ -219,6 +286,17 @@ func lambdaForm(env types.Environment, itArgs types.Iterator) types.Object {
 	return makeUserAppliable(env, declared, body, functionKind)
 }
 
+// call appliable with already evaluated arguments (like callFunc)
+func applyToValues(appliable types.Appliable, env types.Environment, values ...types.Object) types.Object {
+	args := types.NewList(values...)
+	if casted, ok := appliable.(userAppliable); ok {
+		it := args.Iter()
+		defer it.Close()
+		return casted.defaultApply(env, it)
+	}
+	return appliable.Apply(env, args)
+}
+
 func callFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	var res types.Object = types.None
 	arg0, _ := itArgs.Next()
@@ -19,78 +19,292 @@
 package builtins
 
 import (
+	"strings"
+
 	"github.com/dvaumoron/indentlang/parser"
 	"github.com/dvaumoron/indentlang/types"
 )
 
-const openElement types.String = "<"
-const openCloseElement types.String = "</"
-const closeElement types.String = ">"
-const closeOpenElement types.String = "/>"
-const space types.String = " "
-const equalQuote types.String = "=\""
-const quote types.String = "\""
+const htmlDoctype types.String = "html"
+
+const className = "class"
+const styleName = "style"
+
+// user can not directly use this kind of id (# start comment)
+const hiddenDoctypeName = "#doctype"
 
-func addXmlTag(base types.BaseEnvironment, name string) {
-	base.StoreStr(name, createXmlTag(name))
+// the returned form store in Main the lazy application of render on its arguments
+func makeMainForm(render func(types.Environment, types.Iterable) types.Object) types.NativeAppliable {
+	return types.MakeNativeAppliable(func(env types.Environment, itArgs types.Iterator) types.Object {
+		// avoid loss in multiple call case
+		savedArgs := types.NewList().AddAll(itArgs)
+		if positioned, ok := itArgs.(types.Positioned); ok {
+			savedArgs.SetPosition(positioned.Position())
+		}
+		env.StoreStr(MainName, types.MakeNativeAppliable(func(callEnv types.Environment, emptyArgs types.Iterator) types.Object {
+			return render(callEnv, savedArgs)
+		}))
+		return types.None
+	})
 }
 
+// evaluate each argument (any tag, text or nothing), None results are ignored
+func documentMain(env types.Environment, args types.Iterable) types.Object {
+	res := types.NewList()
+	types.ForEach(args, func(arg types.Object) bool {
+		evaluated := arg.Eval(env)
+		if _, ok := evaluated.(types.NoneType); !ok {
+			res.Add(evaluated)
+		}
+		return true
+	})
+	return res
+}
+
+// elements which can not have children in HTML
+var voidElements = map[string]types.NoneType{
+	"area": types.None, "base": types.None, "br": types.None, "col": types.None,
+	"embed": types.None, "hr": types.None, "img": types.None, "input": types.None,
+	"link": types.None, "meta": types.None, "source": types.None, "track": types.None,
+	"wbr": types.None,
+}
+
+func addHtmlTag(base types.BaseEnvironment, name string) {
+	base.StoreStr(name, createHtmlTag(name))
+}
+
+// void elements are written without closing tag (their children are ignored),
+// all other elements always get an explicit closing tag.
+func createHtmlTag(name string) types.NativeAppliable {
+	closeMode := types.HtmlClose
+	if _, void := voidElements[name]; void {
+		closeMode = types.VoidClose
+	}
+	return createTag(name, closeMode)
+}
+
+// elements without children are self-closed.
 func createXmlTag(name string) types.NativeAppliable {
-	wrappedName := types.String(name)
+	return createTag(name, types.XmlClose)
+}
+
+func createTag(name string, closeMode types.CloseMode) types.NativeAppliable {
 	return types.MakeNativeAppliable(func(env types.Environment, itArgs types.Iterator) types.Object {
-		attrs := types.NewList()
-		childs := types.NewList()
-		types.ForEach(itArgs, func(arg types.Object) bool {
-			switch casted := arg.Eval(env).(type) {
-			case types.NoneType:
-				// ignore None
-			case *types.List:
-				if casted.HasCategory(parser.AttributeName) {
-					attrs.Add(casted)
-				} else {
-					childs.Add(casted)
-				}
-			default:
+		element := types.NewElement(name, closeMode)
+		if positioned, ok := itArgs.(types.Positioned); ok {
+			element.Position = positioned.Position()
+		}
+		attrs, childs := splitArgs(env, itArgs)
+		element.Attributes = collectAttributes(attrs)
+		element.Children = childs
+		return element
+	})
+}
+
+// evaluate the arguments and separate the attributes from the childs
+func splitArgs(env types.Environment, itArgs types.Iterator) (*types.List, *types.List) {
+	attrs := types.NewList()
+	childs := types.NewList()
+	types.ForEach(itArgs, func(arg types.Object) bool {
+		switch casted := arg.Eval(env).(type) {
+		case types.NoneType:
+			// ignore None
+		case *types.List:
+			if casted.HasCategory(parser.AttributeName) {
+				attrs.Add(casted)
+			} else {
 				childs.Add(casted)
 			}
-			return true
-		})
-		res := types.NewList(openElement, wrappedName)
-		types.ForEach(attrs, func(value types.Object) bool {
-			attr, ok := value.(types.Iterable)
-			if !ok {
-				return true
-			}
+		default:
+			childs.Add(casted)
+		}
+		return true
+	})
+	return attrs, childs
+}
 
-			itAttr := attr.Iter()
-			defer itAttr.Close()
-			attrName, ok := itAttr.Next()
-			if !ok {
-				return true
+// the class and style attributes are merged and the pairs of a spread attribute (@...attrs) are added
+func collectAttributes(attrs *types.List) types.Attributes {
+	collected := attributes{values: map[string]types.Object{}}
+	types.ForEach(attrs, func(value types.Object) bool {
+		attrName, attrValue, ok := extractPair(value)
+		if ok {
+			if name := extractString(attrName); name == parser.SpreadName {
+				// attrValue should contain pairs (Dict, Go map, List of List, ...)
+				collected.spread(attrValue)
+			} else {
+				collected.set(name, attrValue)
 			}
+		}
+		return true
+	})
+
+	res := make(types.Attributes, 0, len(collected.names))
+	for _, name := range collected.names {
+		res = append(res, types.Attribute{Name: name, Value: collected.values[name]})
+	}
+	return res
+}
+
+// return the two first elements of an Iterable, the second default to true
+func extractPair(object types.Object) (types.Object, types.Object, bool) {
+	iterable, ok := object.(types.Iterable)
+	if !ok {
+		return types.None, types.None, false
+	}
+
+	it := iterable.Iter()
+	defer it.Close()
+	first, ok := it.Next()
+	if !ok {
+		return types.None, types.None, false
+	}
+
+	second, ok := it.Next()
+	if !ok {
+		second = types.Boolean(true)
+	}
+	return first, second, true
+}
 
-			res.Add(space)
-			res.Add(attrName)
-			attrValue, ok := itAttr.Next()
+// keep the attributes in their first appearance order,
+// class and style are merged, for the others the last value wins.
+type attributes struct {
+	names  []string
+	values map[string]types.Object
+}
+
+func (a *attributes) set(name string, value types.Object) {
+	if name == styleName {
+		value = styleValue(value)
+	}
+	previous, ok := a.values[name]
+	if !ok {
+		a.names = append(a.names, name)
+		a.values[name] = value
+		return
+	}
+
+	switch name {
+	case className, styleName:
+		a.values[name] = joinValues(previous, value, " ")
+	default:
+		a.values[name] = value
+	}
+}
+
+func (a *attributes) spread(pairs types.Object) {
+	if iterable, ok := pairs.(types.Iterable); ok {
+		types.ForEach(iterable, func(pair types.Object) bool {
+			name, value, ok := extractPair(pair)
 			if ok {
-				res.Add(equalQuote)
-				res.Add(attrValue)
-				res.Add(quote)
+				a.set(extractString(name), value)
 			}
 			return true
 		})
-		if childs.Size() == 0 {
-			res.Add(closeOpenElement)
-		} else {
-			res.Add(closeElement)
-			types.ForEach(childs, func(value types.Object) bool {
-				res.Add(value)
+	}
+}
+
+func joinValues(value0 types.Object, value1 types.Object, sep string) types.Object {
+	text0, text1 := types.Text(value0), types.Text(value1)
+	switch {
+	case text0 == "":
+		return value1
+	case text1 == "":
+		return value0
+	}
+	return types.String(text0 + sep + text1)
+}
+
+// convert pairs (like a Dict) in "key: value;" declarations, the pairs with a None or false value are ignored
+func styleValue(value types.Object) types.Object {
+	if str, ok := value.(types.String); ok {
+		if trimmed := strings.TrimSpace(string(str)); trimmed != "" && trimmed[len(trimmed)-1] != ';' {
+			return types.String(trimmed + ";")
+		}
+		return value
+	}
+	iterable, ok := value.(types.Iterable)
+	if !ok {
+		return value
+	}
+
+	var declarations []string
+	types.ForEach(iterable, func(pair types.Object) bool {
+		name, value, ok := extractPair(pair)
+		if text := types.Text(value); ok && text != "" {
+			declarations = append(declarations, extractString(name)+": "+text+";")
+		}
+		return true
+	})
+	if len(declarations) == 0 {
+		return types.None
+	}
+	return types.String(strings.Join(declarations, " "))
+}
+
+// each argument is a class name, a pair (name condition) or pairs (like a Dict) of name and condition
+func classesFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	var classes []string
+	addClass := func(name types.Object, condition types.Object) {
+		if text := types.Text(name); text != "" && extractBoolean(condition) {
+			classes = append(classes, text)
+		}
+	}
+	types.ForEach(itArgs, func(arg types.Object) bool {
+		switch casted := arg.Eval(env).(type) {
+		case types.String:
+			addClass(casted, types.Boolean(true))
+		case *types.List:
+			name, condition, _ := extractPair(casted)
+			addClass(name, condition)
+		case types.Iterable:
+			types.ForEach(casted, func(pair types.Object) bool {
+				name, condition, _ := extractPair(pair)
+				addClass(name, condition)
 				return true
 			})
-			res.Add(openCloseElement)
-			res.Add(wrappedName)
-			res.Add(closeElement)
 		}
-		return res
+		return true
 	})
+	if len(classes) == 0 {
+		return types.None
+	}
+	return types.String(strings.Join(classes, " "))
+}
+
+// write the html doctype before the html element, unless disabled with HtmlDoctype
+func makeHtmlMain(elementHtml types.NativeAppliable) func(types.Environment, types.Iterable) types.Object {
+	return func(env types.Environment, args types.Iterable) types.Object {
+		res := types.NewList()
+		doctype, ok := env.LoadStr(hiddenDoctypeName)
+		if !ok {
+			doctype = htmlDoctype
+		}
+		if str, _ := doctype.(types.String); str != "" {
+			res.Add(makeDoctype(str))
+		}
+		res.Add(elementHtml.Apply(env, args))
+		return res
+	}
+}
+
+// HtmlDoctype None disable the html doctype, HtmlDoctype "value" replace it
+func htmlDoctypeForm(env types.Environment, itArgs types.Iterator) types.Object {
+	arg, _ := itArgs.Next()
+	env.StoreStr(hiddenDoctypeName, arg.Eval(env))
+	return types.None
+}
+
+func doctypeFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg, _ := itArgs.Next()
+	str, _ := arg.Eval(env).(types.String)
+	if str == "" {
+		return types.None
+	}
+	return makeDoctype(str)
+}
+
+func makeDoctype(value types.String) types.Object {
+	return types.Markup{Kind: types.DoctypeMarkup, Text: string(value)}
 }
@@ -19,7 +19,12 @@
 package builtins
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/dvaumoron/indentlang/parser"
@@ -29,17 +34,154 @@ import (
 const DefaultExt = ".il"
 const DefaultExtLen = len(DefaultExt)
 
+// start of an import path targeting a named root (like "@shared/layout")
+const rootPrefix = '@'
+
+// separator between name and path in a root declaration (like "shared=/path/to/shared")
+const rootNameSeparator = "="
+
+// Roots are searched in order by the Import directive,
+// a path starting with "@name/" is only searched in Named["name"].
+type SearchPath struct {
+	Roots []string
+	Named map[string]string
+}
+
+// basePath is the first root, each of the other roots is a directory path or a "name=path" declaration
+func MakeSearchPath(basePath string, otherRoots ...string) (SearchPath, error) {
+	basePath, err := filepath.Abs(basePath)
+	if err != nil {
+		return SearchPath{}, err
+	}
+	searchPath := SearchPath{Roots: []string{CheckPath(basePath)}, Named: map[string]string{}}
+	for _, root := range otherRoots {
+		name, path, named := strings.Cut(root, rootNameSeparator)
+		if !named {
+			path = root
+		}
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return SearchPath{}, err
+		}
+		path = CheckPath(path)
+		if !named {
+			searchPath.Roots = append(searchPath.Roots, path)
+		} else if name == "" || name == builtinRootName {
+			return SearchPath{}, errors.New("empty or reserved root name in " + root)
+		} else {
+			searchPath.Named[name] = path
+		}
+	}
+	return searchPath, nil
+}
+
+// return a copy with absolute and cleaned roots ending with a "/"
+func (s SearchPath) normalize() SearchPath {
+	res := SearchPath{Roots: make([]string, 0, len(s.Roots)), Named: make(map[string]string, len(s.Named))}
+	for _, root := range s.Roots {
+		res.Roots = append(res.Roots, cleanRoot(root))
+	}
+	for name, root := range s.Named {
+		res.Named[name] = cleanRoot(root)
+	}
+	return res
+}
+
+func cleanRoot(root string) string {
+	if absRoot, err := filepath.Abs(root); err == nil {
+		root = absRoot
+	}
+	return CheckPath(filepath.Clean(root))
+}
+
+// return the canonical path of the first existing file and its root,
+// the first root is used when none match, an error is returned when the path
+// leaves its root or targets an unknown named root, a path starting with "./" or "../"
+// is relative to dir which belongs to currentRoot
+func (s SearchPath) resolve(filePath string, currentRoot string, dir string) (string, string, error) {
+	if strings.HasPrefix(filePath, "./") || strings.HasPrefix(filePath, "../") {
+		totalPath, err := confine(currentRoot, dir+filePath, filePath)
+		return totalPath, currentRoot, err
+	}
+
+	if filePath[0] == rootPrefix {
+		name, rest, _ := strings.Cut(filePath[1:], "/")
+		root, ok := s.Named[name]
+		if !ok {
+			return "", "", errors.New("unknown root in " + filePath)
+		}
+		totalPath, err := confine(root, root+rest, filePath)
+		return totalPath, root, err
+	}
+
+	for _, root := range s.Roots {
+		totalPath, err := confine(root, root+filePath, filePath)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := os.Stat(totalPath); err == nil {
+			return totalPath, root, nil
+		}
+	}
+	root := s.Roots[0]
+	totalPath, err := confine(root, root+filePath, filePath)
+	return totalPath, root, err
+}
+
+// return the cleaned path or an error if it is outside root (compared segment by segment)
+func confine(root string, path string, filePath string) (string, error) {
+	path = filepath.Clean(path)
+	if !strings.HasPrefix(CheckPath(path), root) {
+		return "", errors.New("path outside of its root : " + filePath)
+	}
+	return path, nil
+}
+
+// identify a search path in the directive cache
+func (s SearchPath) key() string {
+	names := make([]string, 0, len(s.Named))
+	for name := range s.Named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, root := range s.Roots {
+		builder.WriteString(root)
+		builder.WriteByte('\n')
+	}
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteString(rootNameSeparator)
+		builder.WriteString(s.Named[name])
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+type importResult struct {
+	env types.Environment
+	err error
+}
+
 type importRequest struct {
-	basePath  string
-	filePath  string
-	responder chan<- types.Environment
+	searchPath SearchPath
+	root       string
+	totalPath  string
+	responder  chan<- importResult
+}
+
+// the same file can be imported with different search paths and roots,
+// which change the resolution of its own imports
+func (r importRequest) key() string {
+	return r.searchPath.key() + r.root + "\n" + r.totalPath
 }
 
 var requestToImporter chan<- importRequest
 
 type importResponse struct {
-	path string
-	env  types.Environment
+	key    string
+	result importResult
 }
 
 var responseToImporter chan<- importResponse
@@ -53,8 +195,8 @@ func init() {
 }
 
 type moduleCacheValue struct {
-	env      types.Environment
-	waitings []chan<- types.Environment
+	result   importResult
+	waitings []chan<- importResult
 	loaded   bool
 }
 
@@ -65,41 +207,54 @@ func importer(requestReceiver <-chan importRequest, responseReceiver <-chan impo
 	for {
 		select {
 		case request := <-requestReceiver:
-			basePath := request.basePath
-			totalPath := basePath + request.filePath
-			value := moduleCache[totalPath]
+			key := request.key()
+			value := moduleCache[key]
 			if value.loaded {
 				responder := request.responder
-				request.responder <- value.env
+				request.responder <- value.result
 				close(responder)
 			} else {
 				waitings := value.waitings
 				if len(waitings) == 0 {
 					// nobody waiting, trying import
-					go innerImporter(basePath, totalPath)
+					go innerImporter(key, request.searchPath, request.root, request.totalPath)
 				}
 				value.waitings = append(waitings, request.responder)
-				moduleCache[totalPath] = value
+				moduleCache[key] = value
 			}
 		case response := <-responseReceiver:
-			path := response.path
-			env := response.env
+			key := response.key
+			result := response.result
 			// send the imported to all waitings
-			for _, responder := range moduleCache[path].waitings {
-				responder <- env
+			for _, responder := range moduleCache[key].waitings {
+				responder <- result
 				close(responder)
 			}
 			// save the computed env & reset the list of waiting
-			moduleCache[path] = moduleCacheValue{env: env, loaded: true}
+			moduleCache[key] = moduleCacheValue{result: result, loaded: true}
 		}
 	}
 }
 
 const ImportName = "Import"
 
-func innerImporter(basePath string, totalPath string) {
+func innerImporter(key string, searchPath SearchPath, root string, totalPath string) {
+	var result importResult
+	// an error in a nested import fails this one
+	defer func() {
+		if r := recover(); r != nil {
+			callError, ok := r.(types.CallError)
+			if !ok {
+				panic(r)
+			}
+			result = importResult{err: fmt.Errorf("in %s : %w", totalPath, callError)}
+		}
+		responseToImporter <- importResponse{key: key, result: result}
+	}()
+
 	env := types.MakeLocalEnvironment(Builtins)
-	env.StoreStr(ImportName, makeCheckedImportDirective(basePath))
+	// relative imports are resolved from the directory of the imported file
+	env.StoreStr(ImportName, makeImportDirective(searchPath, root, CheckPath(filepath.Dir(totalPath))))
 	// nested environment to isolate the directive Import, this avoid copying
 	var local types.Environment
 	var node types.Object
@@ -108,51 +263,77 @@ func innerImporter(basePath string, totalPath string) {
 		goto End
 	}
 
-	node, err = parser.Parse(string(tmplData))
+	node, err = parser.ParseSource(totalPath, string(tmplData))
 	if err == nil {
-		local = types.MakeLocalEnvironment(env)
-		node.Eval(local)
+		moduleEnv := types.MakeLocalEnvironment(env)
+		node.Eval(moduleEnv)
+		// the elements built at import are used by all the executions
+		types.ForEach(moduleEnv, func(pair types.Object) bool {
+			_, value, _ := extractPair(pair)
+			types.Share(value)
+			return true
+		})
+		local = moduleEnv
 	}
 End:
-	responseToImporter <- importResponse{path: totalPath, env: local}
+	result.env = local
 }
 
 var directiveMutex sync.RWMutex
 var directiveCache = map[string]types.NativeAppliable{}
 
 func MakeImportDirective(basePath string) types.NativeAppliable {
-	return makeCheckedImportDirective(CheckPath(basePath))
+	return MakeSearchPathImportDirective(SearchPath{Roots: []string{basePath}})
+}
+
+// the roots in searchPath are made absolute, relative imports are resolved from the first one,
+// an import leaving its root or targeting an unknown named root raise a types.CallError
+func MakeSearchPathImportDirective(searchPath SearchPath) types.NativeAppliable {
+	searchPath = searchPath.normalize()
+	root := searchPath.Roots[0]
+	return makeImportDirective(searchPath, root, root)
 }
 
-// internal version where basePath must end with a "/"
-func makeCheckedImportDirective(basePath string) types.NativeAppliable {
+// internal version where dir is the directory of the importing file in root
+func makeImportDirective(searchPath SearchPath, root string, dir string) types.NativeAppliable {
+	key := searchPath.key() + root + "\n" + dir
 	directiveMutex.RLock()
-	res, ok := directiveCache[basePath]
+	res, ok := directiveCache[key]
 	directiveMutex.RUnlock()
 	if !ok {
 		directiveMutex.Lock()
-		res, ok = directiveCache[basePath]
+		res, ok = directiveCache[key]
 		if !ok {
 			res = types.MakeNativeAppliable(func(env types.Environment, itArgs types.Iterator) types.Object {
 				arg0, _ := itArgs.Next()
 				filePath, ok := arg0.Eval(env).(types.String)
-				if ok {
-					if end := len(filePath) - DefaultExtLen; filePath[end:] != DefaultExt {
+				if module, isBuiltin := builtinModules[string(filePath)]; isBuiltin {
+					module.CopyTo(env)
+				} else if ok && filePath != "" {
+					if end := len(filePath) - DefaultExtLen; end < 0 || filePath[end:] != DefaultExt {
 						filePath = filePath + DefaultExt
 					}
 
-					response := make(chan types.Environment)
+					totalPath, fileRoot, err := searchPath.resolve(string(filePath), root, dir)
+					if err != nil {
+						panic(types.CallError{Name: ImportName, Err: err})
+					}
+
+					response := make(chan importResult)
 					requestToImporter <- importRequest{
-						basePath: basePath, filePath: string(filePath), responder: response,
+						searchPath: searchPath, root: fileRoot, totalPath: totalPath, responder: response,
+					}
+					result := <-response
+					if result.err != nil {
+						panic(types.CallError{Name: ImportName, Err: result.err})
 					}
-					otherEnv := <-response
-					if otherEnv != nil {
-						otherEnv.CopyTo(env)
+					if result.env != nil {
+						result.env.CopyTo(env)
 					}
 				}
 				return types.None
 			})
-			directiveCache[basePath] = res
+			directiveCache[key] = res
 		}
 		directiveMutex.Unlock()
 	}
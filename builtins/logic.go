@@ -18,7 +18,15 @@
 
 package builtins
 
-import "github.com/dvaumoron/indentlang/types"
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dvaumoron/indentlang/types"
+)
 
 func notFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	arg, _ := itArgs.Next()
@@ -59,27 +67,78 @@ func equals(value0 types.Object, value1 types.Object) bool {
 	case types.Boolean:
 		casted1, ok := value1.(types.Boolean)
 		return ok && (casted0 == casted1)
-	case types.Integer:
-		switch casted1 := value1.(type) {
-		case types.Integer:
-			return casted0 == casted1
-		case types.Float:
-			return types.Float(casted0) == casted1
-		}
-	case types.Float:
-		switch casted1 := value1.(type) {
-		case types.Integer:
-			return casted0 == types.Float(casted1)
-		case types.Float:
-			return casted0 == casted1
-		}
+	case types.Integer, types.Decimal, types.Float:
+		return compareNumber(value0, value1, equalComparator)
 	case types.String:
 		casted1, ok := value1.(types.String)
 		return ok && (casted0 == casted1)
+	case types.Time:
+		casted1, ok := value1.(types.Time)
+		return ok && time.Time(casted0).Equal(time.Time(casted1))
+	case types.Duration:
+		casted1, ok := value1.(types.Duration)
+		return ok && (casted0 == casted1)
+	case *types.List:
+		casted1, ok := value1.(*types.List)
+		return ok && equalLists(casted0, casted1)
+	}
+	if isMapping(value0) && isMapping(value1) {
+		return equalMappings(value0.(types.Iterable), value1.(types.Iterable))
 	}
 	return false
 }
 
+// same size and equal elements (the categories are ignored)
+func equalLists(list0 *types.List, list1 *types.List) bool {
+	elements0, elements1 := listElements(list0), listElements(list1)
+	if len(elements0) != len(elements1) {
+		return false
+	}
+	for index, element := range elements0 {
+		if !equals(element, elements1[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+func listElements(list *types.List) []types.Object {
+	elements := make([]types.Object, 0, list.Size())
+	types.ForEach(list, func(element types.Object) bool {
+		elements = append(elements, element)
+		return true
+	})
+	return elements
+}
+
+// a Dict or a wrapped Go map or struct
+func isMapping(object types.Object) bool {
+	switch object.(type) {
+	case *types.List, types.Environment:
+		return false
+	case *types.Dict:
+		return true
+	}
+	_, iterable := object.(types.Iterable)
+	_, loadable := object.(types.StringLoadable)
+	return iterable && loadable
+}
+
+// same keys with equal values, the order does not matter
+func equalMappings(mapping0 types.Iterable, mapping1 types.Iterable) bool {
+	dict0, dict1 := toDict(mapping0), toDict(mapping1)
+	if dict0.Size() != dict1.Size() {
+		return false
+	}
+	for _, key := range dict0.Keys() {
+		value1, ok := dict1.Get(key)
+		if !ok || !equals(dict0.Load(key), value1) {
+			return false
+		}
+	}
+	return true
+}
+
 func notEqualsFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	arg0, _ := itArgs.Next()
 	arg1, ok := itArgs.Next()
@@ -112,6 +171,10 @@ func lessEqual[O ordered](value0 O, value1 O) bool {
 	return value0 <= value1
 }
 
+func equal[O ordered](value0 O, value1 O) bool {
+	return value0 == value1
+}
+
 var greaterThanComparator = &comparator{
 	compareInt:    greaterThan[int64],
 	compareFloat:  greaterThan[float64],
@@ -127,6 +190,11 @@ var lessThanComparator = &comparator{
 	compareFloat:  lessThan[float64],
 	compareString: lessThan[string],
 }
+var equalComparator = &comparator{
+	compareInt:    equal[int64],
+	compareFloat:  equal[float64],
+	compareString: equal[string],
+}
 var lessEqualComparator = &comparator{
 	compareInt:    lessEqual[int64],
 	compareFloat:  lessEqual[float64],
@@ -169,23 +237,193 @@ func compareFunc(env types.Environment, itArgs types.Iterator, c *comparator) ty
 
 func compare(value0 types.Object, value1 types.Object, c *comparator) bool {
 	switch casted0 := value0.(type) {
-	case types.Integer:
-		switch casted1 := value1.(type) {
-		case types.Integer:
-			return c.compareInt(int64(casted0), int64(casted1))
-		case types.Float:
-			return c.compareFloat(float64(casted0), float64(casted1))
+	case types.Integer, types.Decimal, types.Float:
+		return compareNumber(value0, value1, c)
+	case types.String:
+		casted1, ok := value1.(types.String)
+		return ok && c.compareString(string(casted0), string(casted1))
+	case types.Time:
+		casted1, ok := value1.(types.Time)
+		return ok && c.compareInt(compareTime(time.Time(casted0), time.Time(casted1)), 0)
+	case types.Duration:
+		casted1, ok := value1.(types.Duration)
+		return ok && c.compareInt(int64(casted0), int64(casted1))
+	case *types.List:
+		casted1, ok := value1.(*types.List)
+		if ok {
+			var res int
+			res, ok = orderLists(casted0, casted1)
+			return ok && c.compareInt(int64(res), 0)
+		}
+	}
+	return false
+}
+
+// give -1, 0 or 1, false when the values are not comparable
+func order(value0 types.Object, value1 types.Object) (int, bool) {
+	switch {
+	case equals(value0, value1):
+		return 0, true
+	case compare(value0, value1, lessThanComparator):
+		return -1, true
+	case compare(value0, value1, greaterThanComparator):
+		return 1, true
+	}
+	return 0, false
+}
+
+// lexicographic order : the first different elements decide, otherwise the shortest list is lower,
+// false when the first different elements are not comparable
+func orderLists(list0 *types.List, list1 *types.List) (int, bool) {
+	elements0, elements1 := listElements(list0), listElements(list1)
+	for index, element := range elements0 {
+		if index == len(elements1) {
+			return 1, true
+		}
+		if res, ok := order(element, elements1[index]); !ok || res != 0 {
+			return res, ok
 		}
+	}
+	if len(elements0) < len(elements1) {
+		return -1, true
+	}
+	return 0, true
+}
+
+// rank of the values which are not ordered by kind (Dict, Element, Go data, ...)
+const otherRank = 8
+
+// rank of the kinds of value, used to sort values which are not comparable,
+// NaN is before the other numbers
+func kindRank(value types.Object) int {
+	switch casted := value.(type) {
+	case types.NoneType:
+		return 0
+	case types.Boolean:
+		return 1
 	case types.Float:
-		switch casted1 := value1.(type) {
-		case types.Integer:
-			return c.compareFloat(float64(casted0), float64(casted1))
-		case types.Float:
-			return c.compareFloat(float64(casted0), float64(casted1))
+		if math.IsNaN(float64(casted)) {
+			return 2
 		}
+		return 3
+	case types.Integer, types.Decimal:
+		return 3
 	case types.String:
-		casted1, ok := value1.(types.String)
-		return ok && c.compareString(string(casted0), string(casted1))
+		return 4
+	case types.Time:
+		return 5
+	case types.Duration:
+		return 6
+	case *types.List:
+		return 7
+	}
+	return otherRank
+}
+
+// a total order : the values are ordered by kind (booleans, NaN, numbers, strings, times, durations, lists
+// then the others), the values of a same kind use order, the lists use the total order on their elements
+// and the other values are ordered by their formatted text
+func totalOrder(value0 types.Object, value1 types.Object) int {
+	rank0, rank1 := kindRank(value0), kindRank(value1)
+	if rank0 != rank1 {
+		return rank0 - rank1
+	}
+	switch casted0 := value0.(type) {
+	case types.NoneType:
+		return 0
+	case types.Boolean:
+		if casted0 == value1.(types.Boolean) {
+			return 0
+		}
+		if casted0 {
+			return 1
+		}
+		return -1
+	case *types.List:
+		elements0, elements1 := listElements(casted0), listElements(value1.(*types.List))
+		for index, element := range elements0 {
+			if index == len(elements1) {
+				return 1
+			}
+			if res := totalOrder(element, elements1[index]); res != 0 {
+				return res
+			}
+		}
+		return len(elements0) - len(elements1)
+	}
+	if rank0 != otherRank {
+		if res, ok := order(value0, value1); ok {
+			return res
+		}
+	}
+	return strings.Compare(sortText(value0), sortText(value1))
+}
+
+// the formatted Go value (fmt sorts the map keys), the output text when there is none
+func sortText(value types.Object) string {
+	if goValue, err := types.ToGo(value); err == nil {
+		return fmt.Sprint(goValue)
+	}
+	return types.Text(value)
+}
+
+// Sort iterable keyFunc give a new List sorted with a total order (see totalOrder),
+// keyFunc (optional) give the value to compare for each element
+func sortFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	iterable, ok := arg0.Eval(env).(types.Iterable)
+	if !ok {
+		return types.None
+	}
+	var keyFunc types.Appliable
+	if arg1, ok := itArgs.Next(); ok {
+		if keyFunc, ok = arg1.Eval(env).(types.Appliable); !ok {
+			return types.None
+		}
+	}
+
+	elements := listElements(types.NewList().AddAll(iterable))
+	keys := elements
+	if keyFunc != nil {
+		keys = make([]types.Object, len(elements))
+		for index, element := range elements {
+			keys[index] = applyToValues(keyFunc, env, element)
+		}
+	}
+	indexes := make([]int, len(elements))
+	for index := range indexes {
+		indexes[index] = index
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return totalOrder(keys[indexes[i]], keys[indexes[j]]) < 0
+	})
+	res := types.NewList()
+	for _, index := range indexes {
+		res.Add(elements[index])
+	}
+	return res
+}
+
+// use the promotion rules of the math operators
+func compareNumber(value0 types.Object, value1 types.Object, c *comparator) bool {
+	switch promote(value0, value1) {
+	case integerKind:
+		return c.compareInt(int64(value0.(types.Integer)), int64(value1.(types.Integer)))
+	case decimalKind:
+		return c.compareInt(int64(toDecimal(value0).Cmp(toDecimal(value1))), 0)
+	case floatKind:
+		return c.compareFloat(toFloat(value0), toFloat(value1))
 	}
 	return false
 }
+
+// give -1, 0 or 1 (avoid the overflow of UnixNano)
+func compareTime(t0 time.Time, t1 time.Time) int64 {
+	switch {
+	case t0.Before(t1):
+		return -1
+	case t0.After(t1):
+		return 1
+	}
+	return 0
+}
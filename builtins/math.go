@@ -19,6 +19,8 @@
 package builtins
 
 import (
+	"math"
+
 	"github.com/dvaumoron/indentlang/parser"
 	"github.com/dvaumoron/indentlang/types"
 )
@@ -31,9 +33,10 @@ const floorDivideName = "//"
 const remainderName = "%"
 
 type cumulCarac struct {
-	init       int64
-	cumulInt   func(int64, int64) int64
-	cumulFloat func(float64, float64) float64
+	init         int64
+	cumulInt     func(int64, int64) int64
+	cumulFloat   func(float64, float64) float64
+	cumulDecimal func(types.Decimal, types.Decimal) types.Decimal
 }
 
 type number interface {
@@ -49,10 +52,10 @@ func multNumber[N number](a, b N) N {
 }
 
 var sumCarac = cumulCarac{
-	init: 0, cumulInt: addNumber[int64], cumulFloat: addNumber[float64],
+	init: 0, cumulInt: addNumber[int64], cumulFloat: addNumber[float64], cumulDecimal: types.Decimal.Add,
 }
 var productCarac = cumulCarac{
-	init: 1, cumulInt: multNumber[int64], cumulFloat: multNumber[float64],
+	init: 1, cumulInt: multNumber[int64], cumulFloat: multNumber[float64], cumulDecimal: types.Decimal.Mul,
 }
 
 func sumFunc(env types.Environment, itArgs types.Iterator) types.Object {
@@ -63,13 +66,16 @@ func productFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	return cumulFunc(env, itArgs, productCarac)
 }
 
+// promotion rules : Integer with Decimal give Decimal, any Float give Float
 func cumulFunc(env types.Environment, itArgs types.Iterator, carac cumulCarac) types.Object {
 	cumul := carac.init
 	cumulF := float64(cumul)
+	cumulD := types.DecimalFromInt(cumul)
 	cumulInt := carac.cumulInt
 	cumulFloat := carac.cumulFloat
 	condition := true
 	hasFloat := false
+	hasDecimal := false
 	types.ForEach(itArgs, func(arg types.Object) bool {
 		switch casted := arg.Eval(env).(type) {
 		case types.Integer:
@@ -77,93 +83,195 @@ func cumulFunc(env types.Environment, itArgs types.Iterator, carac cumulCarac) t
 		case types.Float:
 			hasFloat = true
 			cumulF = cumulFloat(cumulF, float64(casted))
+		case types.Decimal:
+			hasDecimal = true
+			cumulD = carac.cumulDecimal(cumulD, casted)
 		default:
 			condition = false
 		}
 		return condition
 	})
 	if condition {
-		if hasFloat {
-			return types.Float(cumulFloat(float64(cumul), cumulF))
-		} else {
+		switch {
+		case hasFloat:
+			return types.Float(cumulFloat(cumulFloat(float64(cumul), cumulD.Float64()), cumulF))
+		case hasDecimal:
+			return carac.cumulDecimal(types.DecimalFromInt(cumul), cumulD)
+		default:
 			return types.Integer(cumul)
 		}
 	}
 	return types.None
 }
 
+type numberKind int
+
+const (
+	notNumber numberKind = iota
+	integerKind
+	decimalKind
+	floatKind
+)
+
+func kindOfNumber(object types.Object) numberKind {
+	switch object.(type) {
+	case types.Integer:
+		return integerKind
+	case types.Decimal:
+		return decimalKind
+	case types.Float:
+		return floatKind
+	}
+	return notNumber
+}
+
+// give the kind of the operation result (the biggest of Integer < Decimal < Float)
+func promote(value0 types.Object, value1 types.Object) numberKind {
+	kind0, kind1 := kindOfNumber(value0), kindOfNumber(value1)
+	if kind0 == notNumber || kind1 == notNumber {
+		return notNumber
+	}
+	if kind0 > kind1 {
+		return kind0
+	}
+	return kind1
+}
+
+// value must be a number
+func toDecimal(value types.Object) types.Decimal {
+	switch casted := value.(type) {
+	case types.Integer:
+		return types.DecimalFromInt(int64(casted))
+	case types.Decimal:
+		return casted
+	}
+	return types.Decimal{}
+}
+
+// value must be a number
+func toFloat(value types.Object) float64 {
+	switch casted := value.(type) {
+	case types.Integer:
+		return float64(casted)
+	case types.Decimal:
+		return casted.Float64()
+	case types.Float:
+		return float64(casted)
+	}
+	return 0
+}
+
 func minusFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	arg0, _ := itArgs.Next()
 	arg1, _ := itArgs.Next()
-	switch casted := arg0.Eval(env).(type) {
-	case types.Integer:
-		switch casted2 := arg1.Eval(env).(type) {
-		case types.Integer:
-			return types.Integer(casted - casted2)
-		case types.Float:
-			return types.Float(float64(casted) - float64(casted2))
-		}
-	case types.Float:
-		switch casted2 := arg1.Eval(env).(type) {
-		case types.Integer:
-			return types.Float(float64(casted) - float64(casted2))
-		case types.Float:
-			return types.Float(casted - casted2)
-		}
+	value0, value1 := arg0.Eval(env), arg1.Eval(env)
+	switch promote(value0, value1) {
+	case integerKind:
+		return value0.(types.Integer) - value1.(types.Integer)
+	case decimalKind:
+		return toDecimal(value0).Sub(toDecimal(value1))
+	case floatKind:
+		return types.Float(toFloat(value0) - toFloat(value1))
 	}
 	return types.None
 }
 
+// give a Float (a Decimal with DecimalDivisionScale when there is a Decimal and no Float), None for a zero divisor
 func divideFunc(env types.Environment, itArgs types.Iterator) types.Object {
 	arg0, _ := itArgs.Next()
 	arg1, _ := itArgs.Next()
-	switch casted := arg0.Eval(env).(type) {
-	case types.Integer:
-		return divideObject(float64(casted), arg1.Eval(env))
-	case types.Float:
-		return divideObject(float64(casted), arg1.Eval(env))
+	value0, value1 := arg0.Eval(env), arg1.Eval(env)
+	switch promote(value0, value1) {
+	case integerKind, floatKind:
+		if divisor := toFloat(value1); divisor != 0 {
+			return types.Float(toFloat(value0) / divisor)
+		}
+	case decimalKind:
+		if res, ok := toDecimal(value0).Quo(toDecimal(value1), types.DecimalDivisionScale, types.RoundHalfEven); ok {
+			return res
+		}
 	}
 	return types.None
 }
 
-func divideObject(a float64, b types.Object) types.Object {
-	switch casted := b.(type) {
-	case types.Integer:
-		if casted != 0 {
-			return types.Float(a / float64(casted))
-		}
-	case types.Float:
-		if casted != 0 {
-			return types.Float(a / float64(casted))
+// Round value places mode give a Decimal with exactly places fractional digits,
+// the mode default to "half-up" (see types.ParseRoundingMode)
+func roundFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	arg1, _ := itArgs.Next()
+	value, ok := extractDecimal(arg0.Eval(env))
+	if !ok {
+		return types.None
+	}
+	places, ok := arg1.Eval(env).(types.Integer)
+	if !ok {
+		return types.None
+	}
+	mode := types.RoundHalfUp
+	if arg2, ok := itArgs.Next(); ok {
+		if mode, ok = types.ParseRoundingMode(extractString(arg2.Eval(env))); !ok {
+			return types.None
 		}
 	}
-	return types.None
+	return value.Round(int32(places), mode)
+}
+
+// the operators of // and % truncate toward zero like the Go operators on integers
+type divisionCarac struct {
+	intOperator     func(int64, int64) int64
+	floatOperator   func(float64, float64) float64
+	decimalOperator func(types.Decimal, types.Decimal) types.Decimal
 }
 
-func floorDivideOperator(a, b int64) int64 {
-	return a / b
+var floorDivideCarac = divisionCarac{
+	intOperator: func(a, b int64) int64 {
+		return a / b
+	},
+	floatOperator: func(a, b float64) float64 {
+		return math.Trunc(a / b)
+	},
+	decimalOperator: func(a, b types.Decimal) types.Decimal {
+		quotient, _, _ := a.QuoRem(b)
+		return quotient
+	},
 }
 
-func remainderOperator(a, b int64) int64 {
-	return a % b
+var remainderCarac = divisionCarac{
+	intOperator: func(a, b int64) int64 {
+		return a % b
+	},
+	floatOperator: math.Mod,
+	decimalOperator: func(a, b types.Decimal) types.Decimal {
+		_, remainder, _ := a.QuoRem(b)
+		return remainder
+	},
 }
 
 func floorDivideFunc(env types.Environment, itArgs types.Iterator) types.Object {
-	return intOperatorFunc(env, itArgs, floorDivideOperator)
+	return divisionOperatorFunc(env, itArgs, floorDivideCarac)
 }
 
 func remainderFunc(env types.Environment, itArgs types.Iterator) types.Object {
-	return intOperatorFunc(env, itArgs, remainderOperator)
+	return divisionOperatorFunc(env, itArgs, remainderCarac)
 }
 
-func intOperatorFunc(env types.Environment, itArgs types.Iterator, intOperator func(int64, int64) int64) types.Object {
+// same promotion rules as cumulFunc, None for a zero divisor
+func divisionOperatorFunc(env types.Environment, itArgs types.Iterator, carac divisionCarac) types.Object {
 	arg0, _ := itArgs.Next()
-	a, ok := arg0.Eval(env).(types.Integer)
-	if ok {
-		arg1, _ := itArgs.Next()
-		b, _ := arg1.Eval(env).(types.Integer)
-		if b != 0 { // non integer and zero are treated the same way thanks to type assertion
-			return types.Integer(intOperator(int64(a), int64(b)))
+	arg1, _ := itArgs.Next()
+	value0, value1 := arg0.Eval(env), arg1.Eval(env)
+	switch promote(value0, value1) {
+	case integerKind:
+		if b := value1.(types.Integer); b != 0 {
+			return types.Integer(carac.intOperator(int64(value0.(types.Integer)), int64(b)))
+		}
+	case decimalKind:
+		if b := toDecimal(value1); b.Sign() != 0 {
+			return carac.decimalOperator(toDecimal(value0), b)
+		}
+	case floatKind:
+		if b := toFloat(value1); b != 0 {
+			return types.Float(carac.floatOperator(toFloat(value0), b))
 		}
 	}
 	return types.None
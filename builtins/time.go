@@ -0,0 +1,215 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package builtins
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+// the names usable in place of a layout
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+// layouts tried in order when parsing without layout
+var defaultParseLayouts = []string{time.RFC3339Nano, timeLayouts["DateTime"], timeLayouts["DateOnly"]}
+
+func resolveLayout(layout string) string {
+	if named, ok := timeLayouts[layout]; ok {
+		return named
+	}
+	return layout
+}
+
+// Time value layout, a String is parsed with the layout (a Go layout or a name like "DateOnly"),
+// without layout RFC 3339, "DateTime" and "DateOnly" are tried, an Integer is a Unix time in seconds
+func timeConvFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	switch casted := arg0.Eval(env).(type) {
+	case types.Time:
+		return casted
+	case types.Integer:
+		return types.Time(time.Unix(int64(casted), 0))
+	case types.String:
+		layouts := defaultParseLayouts
+		if arg1, ok := itArgs.Next(); ok {
+			layouts = []string{resolveLayout(extractString(arg1.Eval(env)))}
+		}
+		for _, layout := range layouts {
+			if parsed, err := time.Parse(layout, string(casted)); err == nil {
+				return types.Time(parsed)
+			}
+		}
+	}
+	return types.None
+}
+
+// a String like "1h30m" is parsed
+func durationConvFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg, _ := itArgs.Next()
+	switch casted := arg.Eval(env).(type) {
+	case types.Duration:
+		return casted
+	case types.String:
+		if duration, err := time.ParseDuration(string(casted)); err == nil {
+			return types.Duration(duration)
+		}
+	}
+	return types.None
+}
+
+func nowFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	return types.Time(time.Now())
+}
+
+func extractTime(object types.Object) (time.Time, bool) {
+	casted, ok := object.(types.Time)
+	return time.Time(casted), ok
+}
+
+// FormatTime t layout, the layout is a Go layout or a name (like "RFC1123" or "DateOnly")
+func formatTimeFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	arg1, _ := itArgs.Next()
+	t, ok := extractTime(arg0.Eval(env))
+	if !ok {
+		return types.None
+	}
+	layout := resolveLayout(extractString(arg1.Eval(env)))
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return types.String(t.Format(layout))
+}
+
+// InZone t "Europe/Paris" give the same instant in the zone ("UTC" and "Local" are accepted)
+func inZoneFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	arg1, _ := itArgs.Next()
+	t, ok := extractTime(arg0.Eval(env))
+	if !ok {
+		return types.None
+	}
+	location, err := time.LoadLocation(extractString(arg1.Eval(env)))
+	if err != nil {
+		return types.None
+	}
+	return types.Time(t.In(location))
+}
+
+// AddDuration t d... add the durations to a Time or to a Duration
+func addDurationFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	base := arg0.Eval(env)
+	var total time.Duration
+	valid := true
+	types.ForEach(itArgs, func(arg types.Object) bool {
+		duration, ok := arg.Eval(env).(types.Duration)
+		total += time.Duration(duration)
+		valid = ok
+		return ok
+	})
+	if valid {
+		switch casted := base.(type) {
+		case types.Time:
+			return types.Time(time.Time(casted).Add(total))
+		case types.Duration:
+			return casted + types.Duration(total)
+		}
+	}
+	return types.None
+}
+
+// TimeDiff t0 t1 give the Duration t0 - t1
+func timeDiffFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	arg1, _ := itArgs.Next()
+	t0, ok0 := extractTime(arg0.Eval(env))
+	t1, ok1 := extractTime(arg1.Eval(env))
+	if !ok0 || !ok1 {
+		return types.None
+	}
+	return types.Duration(t0.Sub(t1))
+}
+
+type timeUnit struct {
+	name     string
+	duration time.Duration
+}
+
+// from the biggest, a month is 30 days and a year 365 days
+var timeUnits = []timeUnit{
+	{name: "year", duration: 365 * 24 * time.Hour},
+	{name: "month", duration: 30 * 24 * time.Hour},
+	{name: "day", duration: 24 * time.Hour},
+	{name: "hour", duration: time.Hour},
+	{name: "minute", duration: time.Minute},
+}
+
+// RelativeTime t reference give a text like "3 days ago" or "in 2 hours" (the reference default to now)
+func relativeTimeFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg0, _ := itArgs.Next()
+	t, ok := extractTime(arg0.Eval(env))
+	if !ok {
+		return types.None
+	}
+	reference := time.Now()
+	if arg1, ok := itArgs.Next(); ok {
+		if reference, ok = extractTime(arg1.Eval(env)); !ok {
+			return types.None
+		}
+	}
+	return types.String(relativeTime(reference.Sub(t)))
+}
+
+func relativeTime(elapsed time.Duration) string {
+	future := elapsed < 0
+	if future {
+		elapsed = -elapsed
+	}
+	for _, unit := range timeUnits {
+		if count := int64(elapsed / unit.duration); count > 0 {
+			text := strconv.FormatInt(count, 10) + " " + unit.name
+			if count > 1 {
+				text += "s"
+			}
+			if future {
+				return "in " + text
+			}
+			return text + " ago"
+		}
+	}
+	return "just now"
+}
@@ -0,0 +1,110 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package builtins
+
+import (
+	"strings"
+
+	"github.com/dvaumoron/indentlang/parser"
+	"github.com/dvaumoron/indentlang/types"
+)
+
+const closeCData = "]]>"
+
+var defaultPrologueAttrs = types.NewList(
+	makeAttribute("version", "1.0"), makeAttribute("encoding", "UTF-8"),
+)
+
+func makeAttribute(name types.String, value types.String) *types.List {
+	attr := types.NewList(name, value)
+	attr.AddCategory(parser.AttributeName)
+	return attr
+}
+
+// (. ns name) give the builder of the element "ns:name"
+type xmlNamespace struct {
+	types.NoneType
+	prefix string
+}
+
+func (n xmlNamespace) LoadStr(name string) (types.Object, bool) {
+	return createXmlTag(n.prefix + name), true
+}
+
+func (n xmlNamespace) Load(key types.Object) types.Object {
+	return types.Load(n, key)
+}
+
+func xmlNamespaceFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg, _ := itArgs.Next()
+	str, _ := arg.Eval(env).(types.String)
+	if str == "" {
+		return types.None
+	}
+	return xmlNamespace{prefix: string(str) + ":"}
+}
+
+// the attributes default to version="1.0" encoding="UTF-8"
+func prologueFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	attrs, _ := splitArgs(env, itArgs)
+	if attrs.Size() == 0 {
+		attrs = defaultPrologueAttrs
+	}
+	return makeProcessing("xml", attrs)
+}
+
+// ProcessingInstruction "target" @attr="value"... give <?target attr="value"?>
+func processingFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	arg, _ := itArgs.Next()
+	target, _ := arg.Eval(env).(types.String)
+	if target == "" {
+		return types.None
+	}
+	attrs, _ := splitArgs(env, itArgs)
+	return makeProcessing(target, attrs)
+}
+
+func makeProcessing(target types.String, attrs *types.List) types.Object {
+	return types.Markup{Kind: types.ProcessingMarkup, Text: string(target), Attributes: collectAttributes(attrs)}
+}
+
+// the text is not escaped, an inner "]]>" is splitted in two sections
+func cdataFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	var builder strings.Builder
+	types.ForEach(itArgs, func(arg types.Object) bool {
+		builder.WriteString(extractString(arg.Eval(env)))
+		return true
+	})
+	text := strings.ReplaceAll(builder.String(), closeCData, "]]]]><![CDATA[>")
+	return types.Markup{Kind: types.CDataMarkup, Text: text}
+}
+
+// the text is not escaped, "--" is not allowed in a comment and is replaced by "- -"
+func commentFunc(env types.Environment, itArgs types.Iterator) types.Object {
+	var builder strings.Builder
+	types.ForEach(itArgs, func(arg types.Object) bool {
+		builder.WriteString(extractString(arg.Eval(env)))
+		return true
+	})
+	text := builder.String()
+	for strings.Contains(text, "--") {
+		text = strings.ReplaceAll(text, "--", "- -")
+	}
+	return types.Markup{Kind: types.CommentMarkup, Text: text}
+}
@@ -0,0 +1,146 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package check
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+var headingElements = map[string]int{"h1": 1, "h2": 2, "h3": 3, "h4": 4, "h5": 5, "h6": 6}
+
+// elements which need a label (some input types are excluded)
+var labelableElements = makeNameSet("input select textarea")
+
+// input types which do not need a label
+var unlabeledInputTypes = makeNameSet("hidden submit reset button image")
+
+// Accessibility report common WCAG failures : img (and area or input of type image) without alt,
+// form fields without label, skipped heading levels, links without text and html without lang.
+// A field is labeled by an ancestor label, a label with a for attribute matching its id,
+// an aria-label, an aria-labelledby or a title.
+func Accessibility(document types.Object) []Diagnostic {
+	labelFor := map[string]struct{}{}
+	walk(document, nil, func(element *types.Element, ancestors []*types.Element) bool {
+		if element.Name == "label" {
+			if id := attrText(element, "for"); id != "" {
+				labelFor[id] = struct{}{}
+			}
+		}
+		return !contains(foreignElements, element.Name)
+	})
+
+	var diagnostics []Diagnostic
+	previousLevel := 0
+	walk(document, nil, func(element *types.Element, ancestors []*types.Element) bool {
+		switch name := element.Name; name {
+		case "html":
+			if attrText(element, "lang") == "" {
+				diagnostics = append(diagnostics, makeDiagnostic("html-lang", element, "missing lang attribute"))
+			}
+		case "img", "area":
+			if _, ok := element.Attr("alt"); !ok {
+				diagnostics = append(diagnostics, makeDiagnostic("img-alt", element, "missing alt attribute"))
+			}
+		case "a":
+			if _, ok := element.Attr("href"); ok && !hasAccessibleName(element) && elementText(element.Children) == "" {
+				diagnostics = append(diagnostics, makeDiagnostic("link-text", element, "link without text"))
+			}
+		default:
+			if level, ok := headingElements[name]; ok {
+				if level > previousLevel+1 {
+					message := "heading level skipped (h" + strconv.Itoa(previousLevel) + " before)"
+					if previousLevel == 0 {
+						message = "heading level skipped (no heading before)"
+					}
+					diagnostics = append(diagnostics, makeDiagnostic("heading-order", element, message))
+				}
+				previousLevel = level
+			}
+		}
+
+		if contains(labelableElements, element.Name) && needLabel(element) && !isLabeled(element, ancestors, labelFor) {
+			diagnostics = append(diagnostics, makeDiagnostic("label", element, "form field without label"))
+		}
+		if element.Name == "input" && strings.ToLower(attrText(element, "type")) == "image" && attrText(element, "alt") == "" {
+			diagnostics = append(diagnostics, makeDiagnostic("img-alt", element, "missing alt attribute"))
+		}
+		return !contains(foreignElements, element.Name)
+	})
+	return diagnostics
+}
+
+func attrText(element *types.Element, name string) string {
+	value, _ := element.Attr(name)
+	return strings.TrimSpace(types.Text(value))
+}
+
+func hasAccessibleName(element *types.Element) bool {
+	return attrText(element, "aria-label") != "" || attrText(element, "aria-labelledby") != "" ||
+		attrText(element, "title") != ""
+}
+
+func needLabel(element *types.Element) bool {
+	return element.Name != "input" || !contains(unlabeledInputTypes, strings.ToLower(attrText(element, "type")))
+}
+
+func isLabeled(element *types.Element, ancestors []*types.Element, labelFor map[string]struct{}) bool {
+	if hasAccessibleName(element) {
+		return true
+	}
+	if _, ok := labelFor[attrText(element, "id")]; ok {
+		return true
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.Name == "label" {
+			return true
+		}
+	}
+	return false
+}
+
+// give the trimmed text content, the alt of the images counts as text
+func elementText(object types.Object) string {
+	var builder strings.Builder
+	var appendText func(types.Object)
+	appendText = func(object types.Object) {
+		switch casted := object.(type) {
+		case *types.Element:
+			if casted.Name == "img" {
+				builder.WriteString(attrText(casted, "alt"))
+			} else if hasAccessibleName(casted) {
+				// the id of aria-labelledby is enough to know that the name is not empty
+				builder.WriteString(attrText(casted, "aria-label") + attrText(casted, "aria-labelledby") + attrText(casted, "title"))
+			} else if casted.Close != types.VoidClose {
+				appendText(casted.Children)
+			}
+		case *types.List:
+			types.ForEach(casted, func(value types.Object) bool {
+				appendText(value)
+				return true
+			})
+		default:
+			builder.WriteString(types.Text(casted))
+		}
+	}
+	appendText(object)
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,110 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+/*
+Package check implements checks on a rendered document (see template.Template.Render).
+
+A checker is usable at render time as a template.Transformer with Transformer.
+*/
+package check
+
+import (
+	"strings"
+
+	"github.com/dvaumoron/indentlang/template"
+	"github.com/dvaumoron/indentlang/types"
+)
+
+type Diagnostic struct {
+	// identify the kind of problem (like "duplicate-id")
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Element string `json:"element"`
+	// position of the element in the template source, when known
+	Position types.Position `json:"position"`
+}
+
+func (d Diagnostic) String() string {
+	var builder strings.Builder
+	if position := d.Position.String(); position != "" {
+		builder.WriteString(position)
+		builder.WriteString(": ")
+	}
+	builder.WriteString(d.Element)
+	builder.WriteString(": ")
+	builder.WriteString(d.Message)
+	builder.WriteString(" (")
+	builder.WriteString(d.Rule)
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+func makeDiagnostic(rule string, element *types.Element, message string) Diagnostic {
+	return Diagnostic{Rule: rule, Message: message, Element: element.Name, Position: element.Position}
+}
+
+// A Checker return the problems found in a document.
+type Checker func(document types.Object) []Diagnostic
+
+// Error is returned by the Transformer of the checkers when there is diagnostics.
+type Error struct {
+	Diagnostics []Diagnostic
+}
+
+func (e Error) Error() string {
+	lines := make([]string, 0, len(e.Diagnostics))
+	for _, diagnostic := range e.Diagnostics {
+		lines = append(lines, diagnostic.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// run all the checkers on the document.
+func Run(document types.Object, checkers ...Checker) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, checker := range checkers {
+		diagnostics = append(diagnostics, checker(document)...)
+	}
+	return diagnostics
+}
+
+// the returned transformer fails the execution with an Error when a checker find problems.
+func Transformer(checkers ...Checker) template.Transformer {
+	return template.TransformerFunc(func(document types.Object, data any) error {
+		if diagnostics := Run(document, checkers...); len(diagnostics) != 0 {
+			return Error{Diagnostics: diagnostics}
+		}
+		return nil
+	})
+}
+
+// walk call visit on each element with its ancestors (the nearest last),
+// when visit return false the children of the element are skipped.
+func walk(object types.Object, ancestors []*types.Element, visit func(*types.Element, []*types.Element) bool) {
+	switch casted := object.(type) {
+	case *types.Element:
+		if visit(casted, ancestors) && casted.Close != types.VoidClose {
+			walk(casted.Children, append(ancestors, casted), visit)
+		}
+	case *types.List:
+		types.ForEach(casted, func(value types.Object) bool {
+			walk(value, ancestors, visit)
+			return true
+		})
+	}
+}
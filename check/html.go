@@ -0,0 +1,268 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package check
+
+import (
+	"strings"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+type nameSet = map[string]struct{}
+
+func makeNameSet(names string) nameSet {
+	res := nameSet{}
+	for _, name := range strings.Fields(names) {
+		res[name] = struct{}{}
+	}
+	return res
+}
+
+func contains(set nameSet, name string) bool {
+	_, ok := set[name]
+	return ok
+}
+
+// the allowed parents of some elements
+var parentRules = map[string]nameSet{
+	"caption":    makeNameSet("table"),
+	"col":        makeNameSet("colgroup table"),
+	"colgroup":   makeNameSet("table"),
+	"dd":         makeNameSet("dl div"),
+	"dt":         makeNameSet("dl div"),
+	"figcaption": makeNameSet("figure"),
+	"legend":     makeNameSet("fieldset"),
+	"li":         makeNameSet("ul ol menu"),
+	"optgroup":   makeNameSet("select"),
+	"option":     makeNameSet("select datalist optgroup"),
+	"rp":         makeNameSet("ruby"),
+	"rt":         makeNameSet("ruby"),
+	"source":     makeNameSet("picture audio video"),
+	"summary":    makeNameSet("details"),
+	"tbody":      makeNameSet("table"),
+	"td":         makeNameSet("tr"),
+	"tfoot":      makeNameSet("table"),
+	"th":         makeNameSet("tr"),
+	"thead":      makeNameSet("table"),
+	"tr":         makeNameSet("table thead tbody tfoot"),
+	"track":      makeNameSet("audio video"),
+}
+
+// elements which can not contain themselves at any depth
+var noSelfNesting = makeNameSet("a button dfn form label meter progress")
+
+// elements which can not contain interactive content
+var noInteractive = makeNameSet("a button")
+
+var interactiveElements = makeNameSet("a button details embed iframe label select textarea")
+
+// the content allowed in p (and in the headings)
+var phrasingElements = makeNameSet(`a abbr area audio b bdi bdo br button canvas cite code data datalist del
+dfn em embed i iframe img input ins kbd label link map mark math meta meter noscript object output
+picture progress q ruby s samp script select slot small span strong sub sup svg template textarea
+time u var video wbr`)
+
+var phrasingOnly = makeNameSet("p h1 h2 h3 h4 h5 h6 pre span em strong b i label")
+
+// elements whose content is not HTML
+var foreignElements = makeNameSet("svg math")
+
+var globalAttributes = makeNameSet(`accesskey autocapitalize autofocus class contenteditable dir draggable
+enterkeyhint hidden id inert inputmode is itemid itemprop itemref itemscope itemtype lang nonce part
+popover role slot spellcheck style tabindex title translate`)
+
+// the specific attributes of the HTML elements (the global ones are allowed everywhere)
+var elementAttributes = map[string]nameSet{
+	"a":          makeNameSet("href target download ping rel hreflang type referrerpolicy"),
+	"abbr":       nil,
+	"address":    nil,
+	"area":       makeNameSet("alt coords shape href target download ping rel referrerpolicy"),
+	"article":    nil,
+	"aside":      nil,
+	"audio":      makeNameSet("src crossorigin preload autoplay loop muted controls"),
+	"b":          nil,
+	"base":       makeNameSet("href target"),
+	"bdi":        nil,
+	"bdo":        nil,
+	"blockquote": makeNameSet("cite"),
+	"body":       nil,
+	"br":         nil,
+	"button": makeNameSet(`disabled form formaction formenctype formmethod formnovalidate formtarget
+name popovertarget popovertargetaction type value`),
+	"canvas":     makeNameSet("width height"),
+	"caption":    nil,
+	"cite":       nil,
+	"code":       nil,
+	"col":        makeNameSet("span"),
+	"colgroup":   makeNameSet("span"),
+	"data":       makeNameSet("value"),
+	"datalist":   nil,
+	"dd":         nil,
+	"del":        makeNameSet("cite datetime"),
+	"details":    makeNameSet("open name"),
+	"dfn":        nil,
+	"dialog":     makeNameSet("open"),
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"embed":      makeNameSet("src type width height"),
+	"fieldset":   makeNameSet("disabled form name"),
+	"figcaption": nil,
+	"figure":     nil,
+	"footer":     nil,
+	"form":       makeNameSet("accept-charset action autocomplete enctype method name novalidate target rel"),
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"head":       nil,
+	"header":     nil,
+	"hgroup":     nil,
+	"hr":         nil,
+	"html":       makeNameSet("manifest xmlns"),
+	"i":          nil,
+	"iframe":     makeNameSet("src srcdoc name sandbox allow allowfullscreen width height referrerpolicy loading"),
+	"img": makeNameSet(`alt src srcset sizes crossorigin usemap ismap width height referrerpolicy decoding
+loading fetchpriority`),
+	"input": makeNameSet(`accept alt autocomplete checked dirname disabled form formaction formenctype
+formmethod formnovalidate formtarget height list max maxlength min minlength multiple name pattern
+placeholder popovertarget popovertargetaction readonly required size src step type value width`),
+	"ins":    makeNameSet("cite datetime"),
+	"kbd":    nil,
+	"label":  makeNameSet("for"),
+	"legend": nil,
+	"li":     makeNameSet("value"),
+	"link": makeNameSet(`href crossorigin rel media integrity hreflang type referrerpolicy sizes imagesrcset
+imagesizes as blocking color disabled fetchpriority`),
+	"main":     nil,
+	"map":      makeNameSet("name"),
+	"mark":     nil,
+	"menu":     nil,
+	"meta":     makeNameSet("name http-equiv content charset media"),
+	"meter":    makeNameSet("value min max low high optimum"),
+	"nav":      nil,
+	"noscript": nil,
+	"object":   makeNameSet("data type name form width height"),
+	"ol":       makeNameSet("reversed start type"),
+	"optgroup": makeNameSet("disabled label"),
+	"option":   makeNameSet("disabled label selected value"),
+	"output":   makeNameSet("for form name"),
+	"p":        nil,
+	"picture":  nil,
+	"pre":      nil,
+	"progress": makeNameSet("value max"),
+	"q":        makeNameSet("cite"),
+	"rp":       nil,
+	"rt":       nil,
+	"ruby":     nil,
+	"s":        nil,
+	"samp":     nil,
+	"script": makeNameSet(`src type nomodule async defer crossorigin integrity referrerpolicy blocking
+fetchpriority`),
+	"section":  nil,
+	"select":   makeNameSet("autocomplete disabled form multiple name required size"),
+	"slot":     makeNameSet("name"),
+	"small":    nil,
+	"source":   makeNameSet("type src srcset sizes media width height"),
+	"span":     nil,
+	"strong":   nil,
+	"style":    makeNameSet("media blocking"),
+	"sub":      nil,
+	"summary":  nil,
+	"sup":      nil,
+	"table":    nil,
+	"tbody":    nil,
+	"td":       makeNameSet("colspan rowspan headers"),
+	"template": makeNameSet("shadowrootmode shadowrootdelegatesfocus shadowrootclonable"),
+	"textarea": makeNameSet("autocomplete cols dirname disabled form maxlength minlength name placeholder readonly required rows wrap"),
+	"tfoot":    nil,
+	"th":       makeNameSet("colspan rowspan headers scope abbr"),
+	"thead":    nil,
+	"time":     makeNameSet("datetime"),
+	"title":    nil,
+	"tr":       nil,
+	"track":    makeNameSet("default kind label src srclang"),
+	"u":        nil,
+	"ul":       nil,
+	"var":      nil,
+	"video":    makeNameSet("src crossorigin poster preload autoplay playsinline loop muted controls width height"),
+	"wbr":      nil,
+}
+
+func isAllowedAttribute(elementName string, attrName string) bool {
+	attrName = strings.ToLower(attrName)
+	return contains(globalAttributes, attrName) || contains(elementAttributes[elementName], attrName) ||
+		strings.HasPrefix(attrName, "data-") || strings.HasPrefix(attrName, "aria-") ||
+		strings.HasPrefix(attrName, "on") || strings.Contains(attrName, ":")
+}
+
+// ValidateHTML report the content-model violations (element in a wrong parent, forbidden nesting,
+// non phrasing content in p, children of void elements), the duplicate ids and the unknown attributes.
+// The content of svg and math is not checked.
+func ValidateHTML(document types.Object) []Diagnostic {
+	var diagnostics []Diagnostic
+	ids := map[string]struct{}{}
+	walk(document, nil, func(element *types.Element, ancestors []*types.Element) bool {
+		name := element.Name
+		if len(ancestors) != 0 {
+			parent := ancestors[len(ancestors)-1]
+			if allowed, ok := parentRules[name]; ok && !contains(allowed, parent.Name) {
+				diagnostics = append(diagnostics, makeDiagnostic("parent", element, "not allowed in "+parent.Name))
+			}
+			if contains(phrasingOnly, parent.Name) && !contains(phrasingElements, name) {
+				diagnostics = append(diagnostics, makeDiagnostic("phrasing-content", element, "not allowed in "+parent.Name))
+			}
+		}
+
+		for index := len(ancestors) - 1; index >= 0; index-- {
+			ancestorName := ancestors[index].Name
+			if ancestorName == name && contains(noSelfNesting, name) {
+				diagnostics = append(diagnostics, makeDiagnostic("nesting", element, "nested in another "+name))
+				break
+			}
+			if contains(noInteractive, ancestorName) && contains(interactiveElements, name) {
+				diagnostics = append(diagnostics, makeDiagnostic("nesting", element, "interactive content not allowed in "+ancestorName))
+				break
+			}
+		}
+
+		if element.Close == types.VoidClose && element.Children.Size() != 0 {
+			diagnostics = append(diagnostics, makeDiagnostic("void", element, "children of a void element are ignored"))
+		}
+
+		_, known := elementAttributes[name]
+		for _, attr := range element.Attributes {
+			if attr.Name == "id" {
+				if id := types.Text(attr.Value); id != "" {
+					if _, ok := ids[id]; ok {
+						diagnostics = append(diagnostics, makeDiagnostic("duplicate-id", element, "duplicate id "+id))
+					}
+					ids[id] = struct{}{}
+				}
+			} else if known && !isAllowedAttribute(name, attr.Name) {
+				diagnostics = append(diagnostics, makeDiagnostic("attribute", element, "attribute "+attr.Name+" not allowed"))
+			}
+		}
+		return !contains(foreignElements, name)
+	})
+	return diagnostics
+}
@@ -19,55 +19,115 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
+	"github.com/dvaumoron/indentlang/check"
 	"github.com/dvaumoron/indentlang/template"
 	"gopkg.in/yaml.v3"
 )
 
+const checkCommand = "check"
+const jsonFlag = "-json"
+
 func main() {
 	args := os.Args
+	if len(args) > 1 && args[1] == checkCommand {
+		args = args[2:]
+		asJson := len(args) != 0 && args[0] == jsonFlag
+		if asJson {
+			args = args[1:]
+		}
+		if len(args) < 2 {
+			fmt.Println("Usage : indentlang check [-json] file.il data.yaml [root | name=root]...")
+			return
+		}
+		if !checkTemplate(args[0], args[1], args[2:], asJson) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(args) < 4 {
-		fmt.Println("Usage : indentlang file.il data.yaml outputFile")
+		fmt.Println("Usage : indentlang file.il data.yaml outputFile [root | name=root]...")
+		fmt.Println("        indentlang check [-json] file.il data.yaml [root | name=root]...")
 		return
 	}
 
 	tmplPath := args[1]
 	dataPath := args[2]
 	outPath := args[3]
+	otherRoots := args[4:]
 
-	tmpl, err := template.ParsePath(tmplPath)
+	tmpl, tmplArgs, err := load(tmplPath, dataPath, otherRoots)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	dataBody, err := os.ReadFile(dataPath)
+	file, err := os.Create(outPath)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
+	defer file.Close()
 
-	tmplArgs := map[string]any{}
-	err = yaml.Unmarshal(dataBody, tmplArgs)
+	err = tmpl.Execute(file, tmplArgs)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	file, err := os.Create(outPath)
+	fmt.Println(outPath, "generated")
+}
+
+func load(tmplPath string, dataPath string, otherRoots []string) (template.Template, map[string]any, error) {
+	tmpl, err := template.ParsePath(tmplPath, otherRoots...)
+	if err != nil {
+		return tmpl, nil, err
+	}
+
+	dataBody, err := os.ReadFile(dataPath)
+	if err != nil {
+		return tmpl, nil, err
+	}
+
+	tmplArgs := map[string]any{}
+	err = yaml.Unmarshal(dataBody, tmplArgs)
+	return tmpl, tmplArgs, err
+}
+
+// render the template with the data and print the diagnostics (one by line or as a JSON array),
+// return false on problem
+func checkTemplate(tmplPath string, dataPath string, otherRoots []string, asJson bool) bool {
+	tmpl, tmplArgs, err := load(tmplPath, dataPath, otherRoots)
 	if err != nil {
 		fmt.Println(err)
-		return
+		return false
 	}
-	defer file.Close()
 
-	err = tmpl.Execute(file, tmplArgs)
+	document, err := tmpl.Render(tmplArgs)
 	if err != nil {
 		fmt.Println(err)
-		return
+		return false
 	}
 
-	fmt.Println(outPath, "generated")
+	diagnostics := check.Run(document, check.ValidateHTML, check.Accessibility)
+	if asJson {
+		if diagnostics == nil {
+			diagnostics = []check.Diagnostic{}
+		}
+		encoded, err := json.MarshalIndent(diagnostics, "", "  ")
+		if err != nil {
+			fmt.Println(err)
+			return false
+		}
+		fmt.Println(string(encoded))
+	} else {
+		for _, diagnostic := range diagnostics {
+			fmt.Println(diagnostic)
+		}
+	}
+	return len(diagnostics) == 0
 }
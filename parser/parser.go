@@ -53,15 +53,23 @@ func newStack[T any]() *stack[T] {
 }
 
 func Parse(str string) (*types.List, error) {
+	return ParseSource("", str)
+}
+
+// source (like a file path) is used in the positions of the parsed lists.
+func ParseSource(source string, str string) (*types.List, error) {
 	indentStack := newStack[int]()
 	indentStack.push(0)
 	listStack := newStack[*types.List]()
 	res := types.NewList(ListId)
+	position := types.Position{Source: source}
+	res.SetPosition(position)
 	listStack.push(res)
-	manageOpen(listStack)
+	manageOpen(listStack, position)
 	var err error
 LineLoop:
-	for _, line := range strings.Split(str, "\n") {
+	for lineIndex, line := range strings.Split(str, "\n") {
+		position.Line = lineIndex + 1
 		if trimmed := strings.TrimSpace(line); trimmed != "" && trimmed[0] != '#' {
 			index := 0
 			var char rune
@@ -69,10 +77,10 @@ LineLoop:
 				if !unicode.IsSpace(char) {
 					if top := indentStack.peek(); top < index {
 						indentStack.push(index)
-						manageOpen(listStack)
+						manageOpen(listStack, position)
 					} else if top == index {
 						listStack.pop()
-						manageOpen(listStack)
+						manageOpen(listStack, position)
 					} else {
 						indentStack.pop()
 						listStack.pop()
@@ -85,14 +93,14 @@ LineLoop:
 							break LineLoop
 						}
 						listStack.pop()
-						manageOpen(listStack)
+						manageOpen(listStack, position)
 					}
 					break
 				}
 			}
 			words := make(chan string)
 			done := make(chan types.NoneType)
-			go handleWord(words, listStack, done)
+			go handleWord(words, listStack, position, done)
 			chars := make(chan rune)
 			go sendChar(chars, line[index:])
 			var buildingWord []rune
@@ -121,17 +129,18 @@ LineLoop:
 	return res, err
 }
 
-func manageOpen(listStack *stack[*types.List]) {
+func manageOpen(listStack *stack[*types.List], position types.Position) {
 	current := types.NewList()
+	current.SetPosition(position)
 	listStack.peek().Add(current)
 	listStack.push(current)
 }
 
-func handleWord(words <-chan string, listStack *stack[*types.List], done chan<- types.NoneType) {
+func handleWord(words <-chan string, listStack *stack[*types.List], position types.Position, done chan<- types.NoneType) {
 	for word := range words {
 		switch word {
 		case "(":
-			manageOpen(listStack)
+			manageOpen(listStack, position)
 		case ")":
 			listStack.pop()
 		default:
@@ -28,6 +28,9 @@ import (
 const SetName = ":="
 const UnquoteName = "Unquote"
 
+// attribute name used to spread pairs in attributes (@...attrs)
+const SpreadName = "..."
+
 var CustomRules = types.NewList()
 
 var wordParsers []types.ConvertString
@@ -39,7 +42,7 @@ var BuiltinsCopy types.Environment = types.MakeBaseEnvironment()
 func init() {
 	wordParsers = []types.ConvertString{
 		parseTrue, parseFalse, parseNone, parseAttribute, parseUnquote,
-		parseList, parseString, parseString2, parseInt, parseFloat,
+		parseList, parseString, parseString2, parseDecimal, parseInt, parseFloat,
 	}
 }
 
@@ -153,6 +156,12 @@ func parseAttribute(word string) (types.Object, bool) {
 	if word[0] != '@' {
 		return nil, false
 	}
+	if spreaded := word[1:]; strings.HasPrefix(spreaded, SpreadName) && len(spreaded) > len(SpreadName) {
+		attr := types.NewList(types.String(SpreadName))
+		attr.AddCategory(AttributeName)
+		HandleClassicWord(spreaded[len(SpreadName):], attr)
+		return attr, true
+	}
 	elems := strings.SplitN(word[1:], "=", 2)
 	attr := types.NewList(types.String(elems[0]))
 	attr.AddCategory(AttributeName)
@@ -214,6 +223,15 @@ func handleSubWord(word string, nodeList *types.List) {
 	}
 }
 
+// like 12.50d
+func parseDecimal(word string) (types.Object, bool) {
+	last := len(word) - 1
+	if last < 1 || word[last] != 'd' {
+		return nil, false
+	}
+	return types.ParseDecimal(word[:last])
+}
+
 func parseInt(word string) (types.Object, bool) {
 	i, err := strconv.ParseInt(word, 10, 64)
 	return types.Integer(i), err == nil
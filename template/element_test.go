@@ -0,0 +1,87 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// an element built at import is shared by the executions, a change must stay in its execution
+func TestSharedElementChangeDoesNotLeak(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"lib.il": ":= Footer (footer @class=\"base\")\n\nFunc SetFooter (value)\n\t[]= Footer \"id\" value\n",
+		"page.il": `Import "lib"
+
+:= Header (script @src="/base.js")
+
+:= Parts (Dict ("nav" (nav @class="base")))
+
+Document
+	If Change
+		[]= Header "src" Src
+	Header
+	If Change
+		SetFooter Src
+	Footer
+	If Change
+		[]= ([] Parts "nav") "id" Src
+	[] Parts "nav"
+	Header
+`,
+	})
+
+	const (
+		want0 = `<script src="/base.js"></script><footer class="base"></footer><nav class="base"></nav><script src="/base.js"></script>`
+		want1 = `<script src="/1.js"></script><footer class="base" id="/1.js"></footer><nav class="base" id="/1.js"></nav><script src="/1.js"></script>`
+	)
+	if got := executeString(t, tmpl, map[string]any{"Change": true, "Src": "/1.js"}); got != want1 {
+		t.Errorf("got %q, want %q", got, want1)
+	}
+	if got := executeString(t, tmpl, map[string]any{"Change": false}); got != want0 {
+		t.Errorf("got %q after a change in a previous execution, want %q", got, want0)
+	}
+
+	// executeString can not be called outside of the test goroutine (it calls t.Fatal)
+	const count = 20
+	results := make([]string, count)
+	errs := make([]error, count)
+	var group sync.WaitGroup
+	for i := 0; i < count; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			var builder strings.Builder
+			errs[i] = tmpl.Execute(&builder, map[string]any{"Change": true, "Src": "/" + strconv.Itoa(i) + ".js"})
+			results[i] = builder.String()
+		}(i)
+	}
+	group.Wait()
+
+	for i := 0; i < count; i++ {
+		want := strings.ReplaceAll(want1, "/1.js", "/"+strconv.Itoa(i)+".js")
+		if errs[i] != nil {
+			t.Errorf("execution %d : %v", i, errs[i])
+		} else if results[i] != want {
+			t.Errorf("got %q, want %q", results[i], want)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+type outputMode int
+
+const (
+	rawMode outputMode = iota
+	prettyMode
+	minifyMode
+)
+
+type options struct {
+	mode   outputMode
+	indent string
+	xml    bool
+}
+
+// Option change the way Execute writes its output.
+type Option func(*options)
+
+// Indented output, each element on its own line unless it only contains text and phrasing elements.
+// The content of pre, textarea, script and style is preserved.
+func Indent(indent string) Option {
+	return func(o *options) {
+		o.mode = prettyMode
+		o.indent = indent
+	}
+}
+
+// Collapse whitespace, drop whitespace around non phrasing elements and drop comments.
+// The content of pre, textarea, script and style is preserved.
+func Minify() Option {
+	return func(o *options) {
+		o.mode = minifyMode
+	}
+}
+
+// XML output : the void elements (like br) are self-closed, a true attribute is written name="name",
+// the text and the attribute values are escaped (the entities and character references are kept, CData
+// give an unescaped text) and, with Indent or Minify, the HTML knowledge of phrasing and raw text elements is not used.
+func Xml() Option {
+	return func(o *options) {
+		o.xml = true
+	}
+}
+
+func makeOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// write object in w with respect to o
+func writeWithOptions(w io.Writer, object types.Object, o options) error {
+	if o.mode == rawMode && !o.xml {
+		_, err := object.WriteTo(w)
+		return err
+	}
+
+	f := formatter{indent: o.indent, rawNames: rawElements, phrasingNames: phrasingElements, xml: o.xml}
+	if o.xml {
+		f.rawNames, f.phrasingNames = nil, nil
+	}
+	nodes := f.buildNodes(object, nil)
+	switch o.mode {
+	case rawMode:
+		for _, n := range nodes {
+			f.writeInline(n)
+		}
+	case prettyMode:
+		f.writePretty(nodes, 0)
+	default:
+		f.writeMinified(nodes, false)
+	}
+	_, err := io.WriteString(w, f.builder.String())
+	return err
+}
+
+// elements whose content is written without any change
+var rawElements = map[string]struct{}{
+	"pre": {}, "textarea": {}, "script": {}, "style": {},
+}
+
+// elements which do not break the text flow
+var phrasingElements = map[string]struct{}{
+	"a": {}, "abbr": {}, "b": {}, "bdi": {}, "bdo": {}, "br": {}, "button": {},
+	"cite": {}, "code": {}, "data": {}, "del": {}, "dfn": {}, "em": {}, "i": {},
+	"img": {}, "input": {}, "ins": {}, "kbd": {}, "label": {}, "mark": {},
+	"meter": {}, "output": {}, "progress": {}, "q": {}, "s": {}, "samp": {},
+	"select": {}, "small": {}, "span": {}, "strong": {}, "sub": {}, "sup": {},
+	"textarea": {}, "time": {}, "u": {}, "var": {}, "wbr": {},
+}
+
+type nodeKind int
+
+const (
+	textNode nodeKind = iota
+	elementNode
+	commentNode
+	// doctype, processing instruction or CDATA section
+	otherNode
+)
+
+type node struct {
+	kind nodeKind
+	// the start tag of an element, the whole text for the others
+	start string
+	// empty for a void or self-closed element
+	end      string
+	raw      string
+	children []*node
+	phrasing bool
+}
+
+func (n *node) isPhrasing() bool {
+	return n.kind == textNode || n.phrasing
+}
+
+type formatter struct {
+	builder       strings.Builder
+	indent        string
+	rawNames      map[string]struct{}
+	phrasingNames map[string]struct{}
+	xml           bool
+}
+
+// append to nodes those of the document tree (the lists are flattened and the adjacent texts merged)
+func (f *formatter) buildNodes(object types.Object, nodes []*node) []*node {
+	switch casted := object.(type) {
+	case *types.List:
+		types.ForEach(casted, func(value types.Object) bool {
+			nodes = f.buildNodes(value, nodes)
+			return true
+		})
+	case *types.Element:
+		_, phrasing := f.phrasingNames[casted.Name]
+		current := &node{kind: elementNode, start: f.startTag(casted), end: f.endTag(casted), phrasing: phrasing}
+		if casted.Close != types.VoidClose {
+			if _, ok := f.rawNames[strings.ToLower(casted.Name)]; ok {
+				current.raw = writeString(casted.Children)
+			} else {
+				current.children = f.buildNodes(casted.Children, nil)
+			}
+		}
+		nodes = append(nodes, current)
+	case types.Markup:
+		current := &node{kind: otherNode, start: f.markup(casted), phrasing: casted.Kind == types.CDataMarkup}
+		if casted.Kind == types.CommentMarkup {
+			current.kind = commentNode
+		}
+		nodes = append(nodes, current)
+	default:
+		text := writeString(casted)
+		if text == "" {
+			break
+		}
+		if f.xml {
+			text = escapeXml(text, false)
+		}
+		if last := len(nodes) - 1; last >= 0 && nodes[last].kind == textNode {
+			nodes[last].start += text
+		} else {
+			nodes = append(nodes, &node{kind: textNode, start: text})
+		}
+	}
+	return nodes
+}
+
+func writeString(object io.WriterTo) string {
+	var builder strings.Builder
+	object.WriteTo(&builder)
+	return builder.String()
+}
+
+// the attribute values of a processing instruction are escaped like those of an element
+func (f *formatter) markup(markup types.Markup) string {
+	if !f.xml || markup.Kind != types.ProcessingMarkup {
+		return writeString(markup)
+	}
+	var builder strings.Builder
+	builder.WriteString("<?")
+	builder.WriteString(markup.Text)
+	writeXmlAttributes(&builder, markup.Attributes)
+	builder.WriteString("?>")
+	return builder.String()
+}
+
+func (f *formatter) startTag(element *types.Element) string {
+	var builder strings.Builder
+	builder.WriteByte('<')
+	builder.WriteString(element.Name)
+	if f.xml {
+		writeXmlAttributes(&builder, element.Attributes)
+	} else {
+		element.Attributes.WriteTo(&builder)
+	}
+	if f.selfClosed(element) {
+		builder.WriteString("/>")
+	} else {
+		builder.WriteByte('>')
+	}
+	return builder.String()
+}
+
+func (f *formatter) endTag(element *types.Element) string {
+	if element.Close == types.VoidClose || f.selfClosed(element) {
+		return ""
+	}
+	return "</" + element.Name + ">"
+}
+
+func (f *formatter) selfClosed(element *types.Element) bool {
+	switch element.Close {
+	case types.XmlClose:
+		return element.Children.Size() == 0
+	case types.VoidClose:
+		return f.xml
+	}
+	return false
+}
+
+// like types.Attributes.WriteTo but a true attribute is written name="name" and the values are escaped
+func writeXmlAttributes(builder *strings.Builder, attributes types.Attributes) {
+	for _, attr := range attributes {
+		var value string
+		switch casted := attr.Value.(type) {
+		case types.NoneType:
+			continue
+		case types.Boolean:
+			if !casted {
+				continue
+			}
+			value = attr.Name
+		default:
+			value = escapeXml(writeString(casted), true)
+		}
+		builder.WriteByte(' ')
+		builder.WriteString(attr.Name)
+		builder.WriteString("=\"")
+		builder.WriteString(value)
+		builder.WriteByte('"')
+	}
+}
+
+// escape <, > and & (unless it starts a predefined entity or a character reference), " too in an attribute value
+func escapeXml(s string, attribute bool) string {
+	var builder strings.Builder
+	for index, char := range s {
+		switch {
+		case char == '<':
+			builder.WriteString("&lt;")
+		case char == '>':
+			builder.WriteString("&gt;")
+		case char == '&' && !isReference(s[index:]):
+			builder.WriteString("&amp;")
+		case char == '"' && attribute:
+			builder.WriteString("&quot;")
+		default:
+			builder.WriteRune(char)
+		}
+	}
+	return builder.String()
+}
+
+// the only entities defined without a DTD
+var predefinedEntities = map[string]struct{}{"amp": {}, "lt": {}, "gt": {}, "quot": {}, "apos": {}}
+
+// s start with "&", report whether it is followed by a predefined entity name or a character code and ";"
+func isReference(s string) bool {
+	end := strings.IndexByte(s, ';')
+	if end < 2 {
+		return false
+	}
+	reference := s[1:end]
+	if code, ok := cutPrefix(reference, "#x"); ok {
+		return code != "" && strings.Trim(code, "0123456789abcdefABCDEF") == ""
+	}
+	if code, ok := cutPrefix(reference, "#"); ok {
+		return code != "" && strings.Trim(code, "0123456789") == ""
+	}
+	_, ok := predefinedEntities[reference]
+	return ok
+}
+
+// strings.CutPrefix is not in Go 1.19
+func cutPrefix(s string, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+func (f *formatter) writeIndent(depth int) {
+	for i := 0; i < depth; i++ {
+		f.builder.WriteString(f.indent)
+	}
+}
+
+func (f *formatter) writePretty(nodes []*node, depth int) {
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode:
+			text := strings.TrimSpace(n.start)
+			if text == "" {
+				continue
+			}
+			f.writeIndent(depth)
+			f.builder.WriteString(text)
+		case elementNode:
+			f.writeIndent(depth)
+			if isInline(n) {
+				f.writeInline(n)
+			} else {
+				f.builder.WriteString(n.start)
+				f.builder.WriteByte('\n')
+				f.writePretty(n.children, depth+1)
+				f.writeIndent(depth)
+				f.builder.WriteString(n.end)
+			}
+		default:
+			f.writeIndent(depth)
+			f.builder.WriteString(n.start)
+		}
+		f.builder.WriteByte('\n')
+	}
+}
+
+// an element is inline when it contains only text and inline phrasing elements
+func isInline(n *node) bool {
+	for _, child := range n.children {
+		if !child.isPhrasing() || (child.kind == elementNode && !isInline(child)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *formatter) writeInline(n *node) {
+	if n.kind != elementNode {
+		f.builder.WriteString(n.start)
+		return
+	}
+	f.builder.WriteString(n.start)
+	f.builder.WriteString(n.raw)
+	for _, child := range n.children {
+		f.writeInline(child)
+	}
+	f.builder.WriteString(n.end)
+}
+
+func (f *formatter) writeMinified(nodes []*node, inPhrasing bool) {
+	last := len(nodes) - 1
+	for index, n := range nodes {
+		switch n.kind {
+		case textNode:
+			text := collapseSpace(n.start)
+			if index == 0 && !inPhrasing || index != 0 && !nodes[index-1].isPhrasing() {
+				text = strings.TrimLeft(text, " ")
+			}
+			if index == last && !inPhrasing || index != last && !nodes[index+1].isPhrasing() {
+				text = strings.TrimRight(text, " ")
+			}
+			f.builder.WriteString(text)
+		case elementNode:
+			f.builder.WriteString(n.start)
+			f.builder.WriteString(n.raw)
+			f.writeMinified(n.children, n.isPhrasing())
+			f.builder.WriteString(n.end)
+		case commentNode:
+			// drop comment
+		default:
+			f.builder.WriteString(n.start)
+		}
+	}
+}
+
+// replace each whitespace sequence with a space
+func collapseSpace(s string) string {
+	var builder strings.Builder
+	previousSpace := false
+	for _, char := range s {
+		if unicode.IsSpace(char) {
+			if !previousSpace {
+				builder.WriteByte(' ')
+			}
+			previousSpace = true
+		} else {
+			builder.WriteRune(char)
+			previousSpace = false
+		}
+	}
+	return builder.String()
+}
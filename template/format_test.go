@@ -0,0 +1,119 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+func executeWith(t *testing.T, tmpl Template, opts ...Option) string {
+	t.Helper()
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, nil, opts...); err != nil {
+		t.Fatal(err)
+	}
+	return builder.String()
+}
+
+func TestIndentKeepCDataIntact(t *testing.T) {
+	tmpl := parseFiles(t, "feed.il", map[string]string{
+		"feed.il": ":= item (XmlTag \"item\")\n:= x (XmlTag \"x\")\nDocument\n\titem\n\t\tCData \"a ]]> b\"\n\t\tx \"y\"\n",
+	})
+
+	want := "<item>\n  <![CDATA[a ]]]]><![CDATA[> b]]>\n  <x>y</x>\n</item>\n"
+	if got := executeWith(t, tmpl, Indent("  ")); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestIndentAndMinify(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"page.il": `html
+	body
+		p "some " (b "bold") "  text"
+		ul
+			li "one"
+		Comment "note"
+		pre "  keep  "
+`,
+	})
+
+	want := `<!DOCTYPE html>
+<html>
+  <body>
+    <p>some <b>bold</b>  text</p>
+    <ul>
+      <li>one</li>
+    </ul>
+    <!-- note -->
+    <pre>  keep  </pre>
+  </body>
+</html>
+`
+	if got := executeWith(t, tmpl, Indent("  ")); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	want = "<!DOCTYPE html><html><body><p>some <b>bold</b> text</p><ul><li>one</li></ul><pre>  keep  </pre></body></html>"
+	if got := executeWith(t, tmpl, Minify()); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestXmlOutput(t *testing.T) {
+	tmpl := parseFiles(t, "feed.il", map[string]string{
+		"feed.il": `:= rss (XmlTag "rss")
+:= item (XmlTag "item")
+Document
+	ProcessingInstruction "xml-stylesheet" @href="/style.xsl?a=1&b=2" @title="A<B &lt; C"
+	rss @version="2.0"
+		item @ok=true @ko=false @href="/feed?a=1&b=2" "News & more &amp; &#233; a<b"
+		item "&nbsp; AT&T; &#xE9; &apos;"
+		br
+		item (CData "a & b")
+`,
+	})
+
+	want := `<?xml-stylesheet href="/style.xsl?a=1&amp;b=2" title="A&lt;B &lt; C"?><rss version="2.0">` +
+		`<item ok="ok" href="/feed?a=1&amp;b=2">News &amp; more &amp; &#233; a&lt;b</item>` +
+		`<item>&amp;nbsp; AT&amp;T; &#xE9; &apos;</item><br/><item><![CDATA[a & b]]></item></rss>`
+	if got := executeWith(t, tmpl, Xml()); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExecuteFragmentWithOptions(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"page.il": "Func Card (title)\n\tReturn (div (h2 title))\n\nDocument\n\tCard \"a\"\n",
+	})
+	set := MakeSet()
+	set.Add("page", tmpl)
+
+	var builder strings.Builder
+	err := set.ExecuteFragment(&builder, "page", "Card", nil, []types.Object{types.String("b")}, Indent("  "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := builder.String(), "<div>\n  <h2>b</h2>\n</div>\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dvaumoron/indentlang/builtins"
+	"github.com/dvaumoron/indentlang/types"
+)
+
+// write the files (relative path to content) in a temporary directory and return it
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImportFromUncleanRoot(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/tpl/page.il": "Import \"lib\"\n\nDocument\n\tTitle\n",
+		"a/tpl/lib.il":  ":= Title (p \"lib\")\n",
+	})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relative, err := filepath.Rel(wd, filepath.Join(dir, "a/tpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, basePath := range []string{relative, dir + "//a/tpl", dir + "/a/other/../tpl"} {
+		tmpl, err := ParseWithImport(builtins.MakeImportDirective(basePath), "page")
+		if err != nil {
+			t.Fatalf("base path %s : %v", basePath, err)
+		}
+		if got := executeString(t, tmpl, nil); got != "<p>lib</p>" {
+			t.Errorf("base path %s : got %q", basePath, got)
+		}
+	}
+}
+
+func TestRejectedImportsFail(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/tpl/outside.il": "Import \"../tpl2/secret\"\n",
+		"a/tpl/unknown.il": "Import \"@missing/lib\"\n",
+		"a/tpl/nested.il":  "Import \"unknown\"\n",
+		"a/tpl2/secret.il": ":= Secret \"secret\"\n",
+	})
+	// a root without an ending "/" must not accept its siblings
+	importDirective := builtins.MakeSearchPathImportDirective(builtins.SearchPath{Roots: []string{dir + "/a/tpl"}})
+
+	for _, name := range []string{"outside", "unknown", "nested"} {
+		_, err := ParseWithImport(importDirective, name)
+		var callError types.CallError
+		if !errors.As(err, &callError) || callError.Name != builtins.ImportName {
+			t.Errorf("%s : got %v, want an import error", name, err)
+		}
+	}
+}
+
+// the imports of a shared module follow the search path of each loader
+func TestModuleImportsDependOnLoader(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"common/page.il":  "Import \"lib\"\n\nDocument\n\tp Color\n",
+		"common/lib.il":   "Import \"@theme/colors\"\n",
+		"dark/colors.il":  ":= Color \"black\"\n",
+		"light/colors.il": ":= Color \"white\"\n",
+	})
+
+	for theme, want := range map[string]string{"dark": "<p>black</p>", "light": "<p>white</p>"} {
+		searchPath, err := builtins.MakeSearchPath(dir+"/common", "theme="+dir+"/"+theme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := ParseWithImport(builtins.MakeSearchPathImportDirective(searchPath), "page")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := executeString(t, tmpl, nil); got != want {
+			t.Errorf("theme %s : got %q, want %q", theme, got, want)
+		}
+	}
+}
+
+// only the other roots are "name=path" declarations
+func TestParsePathInDirectoryWithEqual(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a=b/page.il": "Import \"lib\"\n\nDocument\n\tTitle\n",
+		"a=b/lib.il":  ":= Title (p \"lib\")\n",
+	})
+	tmpl, err := ParsePath(filepath.Join(dir, "a=b/page.il"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := executeString(t, tmpl, nil); got != "<p>lib</p>" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+const renderCount = 1000
+
+// the iterations over an environment, a map and a Dict must not start goroutines,
+// even when the loop is left early by a Return
+func TestIterationsDoNotLeakGoroutines(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"page.il": `Func First (iterable)
+	For pair iterable
+		Count pair
+		Return pair
+
+Document
+	For pair env
+		Count pair
+		p pair
+	For pair m
+		Count pair
+		p pair
+	For pair d
+		Count pair
+		p pair
+	First env
+	First m
+	First d
+`,
+	})
+
+	// counted while an iteration is running
+	maxCount := 0
+	err := tmpl.Funcs(map[string]any{"Count": func(any) {
+		if count := runtime.NumGoroutine(); count > maxCount {
+			maxCount = count
+		}
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := types.MakeBaseEnvironment()
+	env.StoreStr("a", types.Integer(1))
+	env.StoreStr("b", types.Integer(2))
+	dict := types.NewDict()
+	dict.StoreStr("x", types.Integer(1))
+	dict.StoreStr("y", types.Integer(2))
+	data := map[string]any{"env": env, "m": map[string]int{"k1": 1, "k2": 2}, "d": dict}
+
+	before := runtime.NumGoroutine()
+	if err := tmpl.Execute(io.Discard, data); err != nil {
+		t.Fatal(err)
+	}
+	if maxCount > before {
+		t.Errorf("goroutines before rendering : %d, during an iteration : %d", before, maxCount)
+	}
+
+	before = runtime.NumGoroutine()
+	for i := 0; i < renderCount; i++ {
+		if err := tmpl.Execute(io.Discard, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// let a leaked goroutine the time to be counted
+	time.Sleep(10 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines before %d renders : %d, after : %d", renderCount, before, after)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+// A Set groups named templates (see adapter.LoadTemplates),
+// its funcs and transformers are also added to the templates added later.
+type Set struct {
+	templates    map[string]Template
+	funcs        map[string]types.Appliable
+	transformers []Transformer
+}
+
+func MakeSet() Set {
+	return Set{templates: map[string]Template{}, funcs: map[string]types.Appliable{}}
+}
+
+// a zero Set can be used
+func (s *Set) init() {
+	if s.templates == nil {
+		s.templates = map[string]Template{}
+		s.funcs = map[string]types.Appliable{}
+	}
+}
+
+// the funcs and transformers of the set are added to t.
+func (s *Set) Add(name string, t Template) {
+	s.init()
+	t.AddFuncs(s.funcs)
+	t.AddTransformers(s.transformers...)
+	s.templates[name] = t
+}
+
+func (s Set) Lookup(name string) (Template, bool) {
+	t, ok := s.templates[name]
+	return t, ok
+}
+
+// sorted names of the templates in the set.
+func (s Set) Names() []string {
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s Set) ExecuteTemplate(w io.Writer, name string, data any, opts ...Option) error {
+	t, ok := s.templates[name]
+	if !ok {
+		return errors.New("no template named " + name + " in the set")
+	}
+	return t.Execute(w, data, opts...)
+}
+
+// the clone share the parsed templates but has its own funcs and transformers.
+func (s Set) Clone() Set {
+	res := Set{
+		templates: make(map[string]Template, len(s.templates)), funcs: make(map[string]types.Appliable, len(s.funcs)),
+		transformers: append([]Transformer(nil), s.transformers...),
+	}
+	for name, t := range s.templates {
+		res.templates[name] = t.Clone()
+	}
+	for name, f := range s.funcs {
+		res.funcs[name] = f
+	}
+	return res
+}
+
+// add funcs to all the templates of the set, and to the ones added later.
+func (s *Set) AddFuncs(funcs map[string]types.Appliable) {
+	s.init()
+	for name, f := range funcs {
+		s.funcs[name] = f
+	}
+	for name, t := range s.templates {
+		t.AddFuncs(funcs)
+		s.templates[name] = t
+	}
+}
+
+// wrap the Go functions and add them like AddFuncs (see Template.Funcs).
+func (s *Set) Funcs(funcs map[string]any) error {
+	appliables, err := wrapFuncs(funcs)
+	if err == nil {
+		s.AddFuncs(appliables)
+	}
+	return err
+}
+
+func (s Set) ExecuteFragment(w io.Writer, name string, fragmentName string, data any, args []types.Object, opts ...Option) error {
+	t, ok := s.templates[name]
+	if !ok {
+		return errors.New("no template named " + name + " in the set")
+	}
+	return t.ExecuteFragment(w, fragmentName, data, args, opts...)
+}
+
+// add transformers to all the templates of the set, and to the ones added later.
+func (s *Set) AddTransformers(transformers ...Transformer) {
+	s.init()
+	s.transformers = append(s.transformers, transformers...)
+	for name, t := range s.templates {
+		t.AddTransformers(transformers...)
+		s.templates[name] = t
+	}
+}
@@ -29,25 +29,140 @@ import (
 )
 
 type Template struct {
-	env types.Environment
+	env          types.Environment
+	funcs        types.BaseEnvironment
+	transformers *[]Transformer
 }
 
-func (t Template) Execute(w io.Writer, data any) error {
-	main, ok := t.env.LoadStr(builtins.MainName)
+func makeTemplate(env types.Environment) Template {
+	return Template{env: env, funcs: types.MakeBaseEnvironment(), transformers: &[]Transformer{}}
+}
+
+// a zero Template can receive funcs and transformers (even if it can not be executed)
+func (t *Template) init() {
+	if t.transformers == nil {
+		t.funcs = types.MakeBaseEnvironment()
+		t.transformers = &[]Transformer{}
+	}
+}
+
+// funcs are visible from the template body and from the Func and Macro it calls (imported ones included),
+// they can not hide builtins or the template definitions.
+func (t *Template) AddFuncs(funcs map[string]types.Appliable) {
+	t.init()
+	for name, f := range funcs {
+		t.funcs.StoreStr(name, f)
+	}
+}
+
+// Funcs wrap the Go functions with types.WrapFunc (an Appliable is added as is), and add them like AddFuncs.
+// A call with unconvertible arguments or returning a non nil error fails the execution with a types.CallError.
+func (t *Template) Funcs(funcs map[string]any) error {
+	appliables, err := wrapFuncs(funcs)
+	if err == nil {
+		t.AddFuncs(appliables)
+	}
+	return err
+}
+
+func wrapFuncs(funcs map[string]any) (map[string]types.Appliable, error) {
+	res := make(map[string]types.Appliable, len(funcs))
+	for name, f := range funcs {
+		if appliable, ok := f.(types.Appliable); ok {
+			res[name] = appliable
+			continue
+		}
+		appliable, err := types.WrapFunc(name, f)
+		if err != nil {
+			return nil, err
+		}
+		res[name] = appliable
+	}
+	return res, nil
+}
+
+// the transformers are called in order on the document before its serialization.
+func (t *Template) AddTransformers(transformers ...Transformer) {
+	t.init()
+	*t.transformers = append(*t.transformers, transformers...)
+}
+
+// the clone share the parsed template but has its own funcs and transformers.
+func (t Template) Clone() Template {
+	res := makeTemplate(t.env)
+	t.funcs.CopyTo(res.funcs)
+	if t.transformers != nil {
+		*res.transformers = append(*res.transformers, *t.transformers...)
+	}
+	return res
+}
+
+// without option the output is written as produced (see Indent and Minify).
+func (t Template) Execute(w io.Writer, data any, opts ...Option) error {
+	return t.execute(w, builtins.MainName, data, types.NewList(), makeOptions(opts))
+}
+
+// render only the Func or Macro called name with args, with the same data environment
+// and options as Execute.
+func (t Template) ExecuteFragment(w io.Writer, name string, data any, args []types.Object, opts ...Option) error {
+	return t.execute(w, name, data, types.NewList(args...), makeOptions(opts))
+}
+
+func (t Template) execute(w io.Writer, name string, data any, args *types.List, o options) error {
+	res, err := t.render(name, data, args)
+	if err != nil {
+		return err
+	}
+	return writeWithOptions(w, res, o)
+}
+
+// Render give the document built by Execute (transformers included) without writing it,
+// the elements are *types.Element (see types.Walk).
+func (t Template) Render(data any) (types.Object, error) {
+	return t.render(builtins.MainName, data, types.NewList())
+}
+
+func (t Template) render(name string, data any, args *types.List) (res types.Object, err error) {
+	if t.env == nil {
+		return nil, errors.New("template not initialized")
+	}
+	object, ok := t.env.LoadStr(name)
 	if !ok {
-		return errors.New("cannot load object Main")
+		return nil, errors.New("cannot load object " + name)
 	}
-	mainAppliable, ok := main.(types.Appliable)
+	appliable, ok := object.(types.Appliable)
 	if !ok {
-		return errors.New("the object Main is not an Appliable")
+		return nil, errors.New("the object " + name + " is not an Appliable")
 	}
+	defer recoverCallError(&err)
 	// each call must have its environment to avoid conflict in parallele execution
-	local := types.MakeLocalEnvironment(t.env)
-	_, err := mainAppliable.ApplyWithData(data, local, types.NewList()).WriteTo(w)
-	return err
+	local := builtins.MakeExecutionEnvironment(t.env, t.funcs)
+	res = appliable.ApplyWithData(data, local, args)
+	if len(*t.transformers) != 0 {
+		// elements built at import are shared between executions
+		res = types.CopyTree(res)
+	}
+	for _, transformer := range *t.transformers {
+		if err := transformer.Transform(res, data); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func recoverCallError(err *error) {
+	if r := recover(); r != nil {
+		callError, ok := r.(types.CallError)
+		if !ok {
+			panic(r)
+		}
+		*err = callError
+	}
 }
 
-func ParsePath(path string) (Template, error) {
+// otherRoots are searched in order by the Import directive after the directory of path,
+// a "name=path" declaration allow Import "@name/file" (see builtins.MakeSearchPath).
+func ParsePath(path string, otherRoots ...string) (Template, error) {
 	path, err := filepath.Abs(path)
 	if err != nil {
 		return Template{}, err
@@ -55,15 +170,21 @@ func ParsePath(path string) (Template, error) {
 
 	splitIndex := strings.LastIndex(path, "/") + 1
 	basePath, fileName := path[:splitIndex], path[splitIndex:]
-	return ParseWithImport(builtins.MakeImportDirective(basePath), fileName), nil
+	searchPath, err := builtins.MakeSearchPath(basePath, otherRoots...)
+	if err != nil {
+		return Template{}, err
+	}
+	return ParseWithImport(builtins.MakeSearchPathImportDirective(searchPath), fileName)
 }
 
-// if the file extension is missing, will add .il
-func ParseWithImport(importDirective types.Appliable, filePath string) Template {
+// if the file extension is missing, will add .il,
+// the error come from an import rejected by importDirective (see types.CallError)
+func ParseWithImport(importDirective types.Appliable, filePath string) (_ Template, err error) {
+	defer recoverCallError(&err)
 	env := types.MakeLocalEnvironment(builtins.Builtins)
 	env.StoreStr(builtins.ImportName, importDirective)
 
 	importDirective.Apply(env, types.NewList(types.String(filePath)))
 
-	return Template{env: env}
+	return makeTemplate(env), nil
 }
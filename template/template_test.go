@@ -0,0 +1,183 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+// write the files (name to content) in a temporary directory and parse the first one
+func parseFiles(t *testing.T, main string, files map[string]string) Template {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpl, err := ParsePath(filepath.Join(dir, main))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+func executeString(t *testing.T, tmpl Template, data any) string {
+	t.Helper()
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		t.Fatal(err)
+	}
+	return builder.String()
+}
+
+func TestFuncsVisibleFromFunc(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"lib.il": "Func Shout (x)\n\tReturn (p (Upper x))\n",
+		"page.il": `Import "lib"
+
+Func Comp (x)
+	Return (p (Upper x))
+
+Macro Wrap (x)
+	Return (div (Upper x))
+
+Func Outer (x)
+	Return (Comp x)
+
+Document
+	Comp "a"
+	Shout "b"
+	Wrap "c"
+	Outer "d"
+`,
+	})
+	if err := tmpl.Funcs(map[string]any{"Upper": strings.ToUpper}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "<p>A</p><p>B</p><div>C</div><p>D</p>"
+	if got := executeString(t, tmpl, nil); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestAddFuncsVisibleFromFunc(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"page.il": "Func Comp (x)\n\tReturn (p (Twice x))\n\nDocument\n\tComp \"a\"\n",
+	})
+	set := MakeSet()
+	set.Add("page", tmpl)
+	set.AddFuncs(map[string]types.Appliable{
+		"Twice": types.MakeNativeAppliable(func(env types.Environment, itArgs types.Iterator) types.Object {
+			arg, _ := itArgs.Next()
+			str, _ := arg.Eval(env).(types.String)
+			return str + str
+		}),
+	})
+
+	var builder strings.Builder
+	if err := set.ExecuteTemplate(&builder, "page", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := builder.String(), "<p>aa</p>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFuncsDoNotHideDefinitions(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"page.il": "Func Upper (x)\n\tReturn x\n\nFunc Comp (x)\n\tReturn (p (Upper x))\n\nDocument\n\tComp \"a\"\n",
+	})
+	if err := tmpl.Funcs(map[string]any{"Upper": strings.ToUpper}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := executeString(t, tmpl, nil), "<p>a</p>"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestZeroTemplateAndSet(t *testing.T) {
+	var tmpl Template
+	if err := tmpl.Funcs(map[string]any{"Upper": strings.ToUpper}); err != nil {
+		t.Fatal(err)
+	}
+	tmpl.AddTransformers(LazyLoading())
+	if err := tmpl.Execute(io.Discard, nil); err == nil {
+		t.Error("a zero Template must fail its execution")
+	}
+
+	var set Set
+	set.AddFuncs(map[string]types.Appliable{})
+	set.Add("zero", Template{})
+	if _, ok := set.Lookup("zero"); !ok {
+		t.Error("template not added to a zero Set")
+	}
+}
+
+// the funcs and transformers of a Set also apply to the templates added later
+func TestSetAppliesToLaterTemplates(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"page.il": "Document\n\tp (Upper \"a\")\n",
+	})
+	var set Set
+	if err := set.Funcs(map[string]any{"Upper": strings.ToUpper}); err != nil {
+		t.Fatal(err)
+	}
+	set.AddTransformers(ElementTransformer(func(element *types.Element, data any) {
+		element.Attributes = append(element.Attributes, types.Attribute{Name: "id", Value: types.String("x")})
+	}))
+	set.Add("page", tmpl)
+
+	var builder strings.Builder
+	if err := set.ExecuteTemplate(&builder, "page", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := builder.String(), `<p id="x">A</p>`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// Sort give the same order whatever the input order, with NaN and values which are not comparable
+func TestSortIsDeterministic(t *testing.T) {
+	tmpl := parseFiles(t, "page.il", map[string]string{
+		"page.il": "Document\n\tFor value (Sort values)\n\t\tIf (Has value \"k\")\n\t\t\tp ([] value \"k\")\n\t\t\tp value\n",
+	})
+	dictA, dictB := types.NewDict(), types.NewDict()
+	dictA.StoreStr("k", types.String("a"))
+	dictB.StoreStr("k", types.String("b"))
+	values := []any{dictB, 2.5, "x", math.NaN(), 1, dictA, math.NaN(), 3}
+
+	const want = "<p>NaN</p><p>NaN</p><p>1</p><p>2.5</p><p>3</p><p>x</p><p>a</p><p>b</p>"
+	for i := 0; i < len(values); i++ {
+		if got := executeString(t, tmpl, map[string]any{"values": values}); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		// rotate the input
+		values = append(values[1:], values[0])
+	}
+}
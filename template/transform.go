@@ -0,0 +1,114 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package template
+
+import (
+	"strings"
+
+	"github.com/dvaumoron/indentlang/types"
+)
+
+// A Transformer change the document before its serialization,
+// data is the one given to Execute.
+type Transformer interface {
+	Transform(document types.Object, data any) error
+}
+
+type TransformerFunc func(types.Object, any) error
+
+func (f TransformerFunc) Transform(document types.Object, data any) error {
+	return f(document, data)
+}
+
+// call transform on each element of the document.
+func ElementTransformer(transform func(*types.Element, any)) Transformer {
+	return TransformerFunc(func(document types.Object, data any) error {
+		types.Walk(document, func(element *types.Element) bool {
+			transform(element, data)
+			return true
+		})
+		return nil
+	})
+}
+
+// add "noopener" to the rel attribute of the a elements with an absolute href (http, https or //).
+func NoopenerExternalLinks() Transformer {
+	return ElementTransformer(func(element *types.Element, data any) {
+		if element.Name != "a" {
+			return
+		}
+		href, _ := element.Attr("href")
+		if !isExternal(types.Text(href)) {
+			return
+		}
+		rel, _ := element.Attr("rel")
+		relText := types.Text(rel)
+		for _, value := range strings.Fields(relText) {
+			if value == "noopener" {
+				return
+			}
+		}
+		element.SetAttr("rel", types.String(strings.TrimSpace(relText+" noopener")))
+	})
+}
+
+func isExternal(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "//")
+}
+
+// add loading="lazy" to the img and iframe elements without loading attribute.
+func LazyLoading() Transformer {
+	return ElementTransformer(func(element *types.Element, data any) {
+		if element.Name != "img" && element.Name != "iframe" {
+			return
+		}
+		if _, ok := element.Attr("loading"); !ok {
+			element.SetAttr("loading", types.String("lazy"))
+		}
+	})
+}
+
+// apply rewrite to the value of the href, src and poster attributes.
+func RewriteURLs(rewrite func(string) string) Transformer {
+	return ElementTransformer(func(element *types.Element, data any) {
+		for index, attr := range element.Attributes {
+			switch attr.Name {
+			case "href", "src", "poster":
+				if url := types.Text(attr.Value); url != "" {
+					element.Attributes[index].Value = types.String(rewrite(url))
+				}
+			}
+		}
+	})
+}
+
+// set the nonce attribute of the script and style elements,
+// the nonce is computed once per execution from the data.
+func CSPNonce(nonce func(data any) string) Transformer {
+	return TransformerFunc(func(document types.Object, data any) error {
+		value := types.String(nonce(data))
+		types.Walk(document, func(element *types.Element) bool {
+			if element.Name == "script" || element.Name == "style" {
+				element.SetAttr("nonce", value)
+			}
+			return true
+		})
+		return nil
+	})
+}
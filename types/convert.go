@@ -0,0 +1,386 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ToGo give the natural Go value of an Object : None give nil, Boolean, Integer, Float, String, Time and Duration
+// give bool, int64, float64, string, time.Time and time.Duration, a Decimal give its exact string (like "12.50"), a Dict give a map[string]any (map[any]any with Integer keys), a List (or another Iterable) give a []any,
+// a wrapped Go value give it back and the other Objects (like Appliable or *Element) are kept.
+func ToGo(object Object) (any, error) {
+	switch casted := object.(type) {
+	case nil, NoneType:
+		return nil, nil
+	case Boolean:
+		return bool(casted), nil
+	case Integer:
+		return int64(casted), nil
+	case Float:
+		return float64(casted), nil
+	case String:
+		return string(casted), nil
+	case Time:
+		return time.Time(casted), nil
+	case Duration:
+		return time.Duration(casted), nil
+	case Decimal:
+		return casted.String(), nil
+	case dataWrapper:
+		if casted.inner.CanInterface() {
+			return casted.inner.Interface(), nil
+		}
+	case *Dict:
+		return dictToGo(casted)
+	case Iterable:
+		if _, ok := casted.(Appliable); ok {
+			return casted, nil
+		}
+		res := []any{}
+		var err error
+		index := 0
+		ForEach(casted, func(value Object) bool {
+			var goValue any
+			if goValue, err = ToGo(value); err != nil {
+				err = fmt.Errorf("element %d : %w", index, err)
+				return false
+			}
+			res = append(res, goValue)
+			index++
+			return true
+		})
+		return res, err
+	}
+	return object, nil
+}
+
+// a map[string]any when all the keys are String, a map[any]any otherwise
+func dictToGo(dict *Dict) (any, error) {
+	stringKeys := make(map[string]any, dict.Size())
+	anyKeys := make(map[any]any, dict.Size())
+	allString := true
+	for _, key := range dict.keys {
+		goValue, err := ToGo(dict.values[key])
+		if err != nil {
+			return nil, fmt.Errorf("key %s : %w", objectText(key), err)
+		}
+		goKey, _ := ToGo(key)
+		anyKeys[goKey] = goValue
+		if str, ok := key.(String); ok && allString {
+			stringKeys[string(str)] = goValue
+		} else {
+			allString = false
+		}
+	}
+	if allString {
+		return stringKeys, nil
+	}
+	return anyKeys, nil
+}
+
+// ToGoAs convert the Object to the type T : structs and maps are filled from pairs (like a Dict,
+// the struct fields follow the il tag), slices and arrays from a List, an error is returned on
+// a type mismatch, an unknown field or an overflow.
+func ToGoAs[T any](object Object) (T, error) {
+	var res T
+	value, err := toValue(object, reflect.TypeOf(&res).Elem())
+	if err == nil && value.IsValid() {
+		// a nil interface value keep the zero T
+		res, _ = value.Interface().(T)
+	}
+	return res, err
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func unmarshalableText(object Object) (string, bool) {
+	switch casted := object.(type) {
+	case String:
+		return string(casted), true
+	case Decimal:
+		return casted.String(), true
+	}
+	return "", false
+}
+
+// false when the Decimal has a fractional part or does not fit in an int64
+func decimalToInteger(d Decimal) (Integer, bool) {
+	integer, ok := d.Int64()
+	return Integer(integer), ok && d.Cmp(DecimalFromInt(integer)) == 0
+}
+
+func mismatch(object Object, target reflect.Type) error {
+	return fmt.Errorf("%w : %T to %v", ErrNotConvertible, object, target)
+}
+
+// convert object to a value of the type target
+func toValue(object Object, target reflect.Type) (reflect.Value, error) {
+	if object == nil {
+		object = None
+	}
+	objectValue := reflect.ValueOf(object)
+	if objectValue.Type().AssignableTo(target) && !(target.Kind() == reflect.Interface && target.NumMethod() == 0) {
+		// the target directly handle an Object
+		return objectValue, nil
+	}
+	if wrapper, ok := object.(dataWrapper); ok && wrapper.inner.CanInterface() {
+		if inner := wrapper.inner; inner.Type().AssignableTo(target) {
+			return inner, nil
+		} else if target.Kind() == reflect.Pointer && inner.Type().AssignableTo(target.Elem()) && inner.CanAddr() {
+			return inner.Addr(), nil
+		}
+	}
+
+	switch casted := object.(type) {
+	case Time:
+		if timeType.AssignableTo(target) {
+			return reflect.ValueOf(time.Time(casted)), nil
+		}
+	case Duration:
+		if durationType.AssignableTo(target) {
+			return reflect.ValueOf(time.Duration(casted)), nil
+		}
+	case String:
+		if target == durationType {
+			duration, err := time.ParseDuration(string(casted))
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%w : %q to %v : %v", ErrNotConvertible, string(casted), target, err)
+			}
+			return reflect.ValueOf(duration), nil
+		}
+	}
+
+	res := reflect.New(target).Elem()
+	if text, ok := unmarshalableText(object); ok && reflect.PointerTo(target).Implements(textUnmarshalerType) {
+		// a Decimal fill a decimal type like *big.Rat
+		if err := res.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return res, fmt.Errorf("%w : %q to %v : %v", ErrNotConvertible, text, target, err)
+		}
+		return res, nil
+	}
+	if casted, ok := object.(Decimal); ok {
+		if integer, ok := decimalToInteger(casted); ok {
+			// an exact Decimal fill an integer
+			object = integer
+		}
+	}
+	switch target.Kind() {
+	case reflect.Bool:
+		if casted, ok := object.(Boolean); ok {
+			res.SetBool(bool(casted))
+			return res, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if casted, ok := object.(Integer); ok {
+			if res.OverflowInt(int64(casted)) {
+				return res, fmt.Errorf("%d overflows %v", casted, target)
+			}
+			res.SetInt(int64(casted))
+			return res, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if casted, ok := object.(Integer); ok {
+			if casted < 0 || res.OverflowUint(uint64(casted)) {
+				return res, fmt.Errorf("%d overflows %v", casted, target)
+			}
+			res.SetUint(uint64(casted))
+			return res, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch casted := object.(type) {
+		case Integer:
+			res.SetFloat(float64(casted))
+			return res, nil
+		case Decimal:
+			res.SetFloat(casted.Float64())
+			return res, nil
+		case Float:
+			res.SetFloat(float64(casted))
+			return res, nil
+		}
+	case reflect.String:
+		switch casted := object.(type) {
+		case String:
+			res.SetString(string(casted))
+			return res, nil
+		case Decimal:
+			res.SetString(casted.String())
+			return res, nil
+		}
+	case reflect.Slice:
+		if casted, ok := object.(String); ok && target.Elem().Kind() == reflect.Uint8 {
+			res.SetBytes([]byte(casted))
+			return res, nil
+		}
+		if iterable, ok := object.(Iterable); ok {
+			err := forEachIndexed(iterable, func(index int, value Object) error {
+				elem, err := toValue(value, target.Elem())
+				if err == nil {
+					res = reflect.Append(res, elem)
+				}
+				return err
+			})
+			if err == nil && res.IsNil() {
+				// an empty List give an empty slice
+				res = reflect.MakeSlice(target, 0, 0)
+			}
+			return res, err
+		}
+	case reflect.Array:
+		if iterable, ok := object.(Iterable); ok {
+			size := 0
+			err := forEachIndexed(iterable, func(index int, value Object) error {
+				if index >= target.Len() {
+					return fmt.Errorf("more than %d elements for %v", target.Len(), target)
+				}
+				elem, err := toValue(value, target.Elem())
+				if err == nil {
+					res.Index(index).Set(elem)
+					size++
+				}
+				return err
+			})
+			if err == nil && size != target.Len() {
+				err = fmt.Errorf("%d elements for %v", size, target)
+			}
+			return res, err
+		}
+	case reflect.Map:
+		if iterable, ok := object.(Iterable); ok {
+			res.Set(reflect.MakeMap(target))
+			err := forEachPair(iterable, func(key Object, value Object) error {
+				keyValue, err := toValue(key, target.Key())
+				if err != nil {
+					return fmt.Errorf("key %s : %w", objectText(key), err)
+				}
+				elem, err := toValue(value, target.Elem())
+				if err != nil {
+					return fmt.Errorf("key %s : %w", objectText(key), err)
+				}
+				res.SetMapIndex(keyValue, elem)
+				return nil
+			})
+			return res, err
+		}
+	case reflect.Struct:
+		if iterable, ok := object.(Iterable); ok {
+			fields := structFields(target)
+			err := forEachPair(iterable, func(key Object, value Object) error {
+				name := objectText(key)
+				field, ok := findField(fields, name)
+				if !ok {
+					return fmt.Errorf("no field %s in %v", name, target)
+				}
+				fieldValue := fieldByIndex(res, field.index)
+				elem, err := toValue(value, fieldValue.Type())
+				if err != nil {
+					return fmt.Errorf("field %s : %w", name, err)
+				}
+				fieldValue.Set(elem)
+				return nil
+			})
+			return res, err
+		}
+	case reflect.Pointer:
+		if _, ok := object.(NoneType); ok {
+			return res, nil
+		}
+		elem, err := toValue(object, target.Elem())
+		if err != nil {
+			return res, err
+		}
+		res.Set(reflect.New(target.Elem()))
+		res.Elem().Set(elem)
+		return res, nil
+	case reflect.Interface:
+		goValue, err := ToGo(object)
+		if err != nil {
+			return res, err
+		}
+		if goValue == nil {
+			return res, nil
+		}
+		if natural := reflect.ValueOf(goValue); natural.Type().AssignableTo(target) {
+			res.Set(natural)
+			return res, nil
+		}
+	}
+	return res, mismatch(object, target)
+}
+
+func forEachIndexed(iterable Iterable, action func(int, Object) error) error {
+	var err error
+	index := 0
+	ForEach(iterable, func(value Object) bool {
+		if err = action(index, value); err != nil {
+			err = fmt.Errorf("element %d : %w", index, err)
+			return false
+		}
+		index++
+		return true
+	})
+	return err
+}
+
+// each element must be a pair (a List of two elements)
+func forEachPair(iterable Iterable, action func(Object, Object) error) error {
+	var err error
+	ForEach(iterable, func(pair Object) bool {
+		casted, ok := pair.(*List)
+		if !ok || len(casted.inner) != 2 {
+			err = fmt.Errorf("%T is not a pair", pair)
+		} else {
+			err = action(casted.inner[0], casted.inner[1])
+		}
+		return err == nil
+	})
+	return err
+}
+
+// allocate the nil embedded pointers on the way
+func fieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for depth, fieldIndex := range index {
+		if depth != 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value
+}
+
+// the exact name is preferred
+func findField(fields []structField, name string) (structField, bool) {
+	if index := indexField(fields, name); index != -1 {
+		return fields[index], true
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.name, name) {
+			return field, true
+		}
+	}
+	return structField{}, false
+}
@@ -0,0 +1,300 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The scale of the result of a division involving a Decimal (rounded with RoundHalfEven).
+const DecimalDivisionScale = 16
+
+// Decimal is an exact decimal number (an unscaled integer and a number of fractional digits),
+// it is written with all its fractional digits ("12.50d" give 12.50).
+type Decimal struct {
+	// never modified after creation
+	unscaled *big.Int
+	scale    int32
+}
+
+var bigTen = big.NewInt(10)
+
+func pow10(exponent int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(exponent)), nil)
+}
+
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+func DecimalFromInt(i int64) Decimal {
+	return Decimal{unscaled: big.NewInt(i)}
+}
+
+// use the shortest decimal representation of f, false for infinities and NaN
+func DecimalFromFloat(f float64) (Decimal, bool) {
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// ParseDecimal accept an optional sign, digits and an optional fractional part ("-12.50").
+func ParseDecimal(s string) (Decimal, bool) {
+	digits := s
+	if digits != "" && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if intPart == "" && fracPart == "" || !onlyDigits(intPart) || !onlyDigits(fracPart) {
+		return Decimal{}, false
+	}
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, false
+	}
+	if s[0] == '-' {
+		unscaled.Neg(unscaled)
+	}
+	return Decimal{unscaled: unscaled, scale: int32(len(fracPart))}, true
+}
+
+func onlyDigits(s string) bool {
+	for _, char := range s {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// give the same value with at least scale fractional digits
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale <= d.scale {
+		return d.value()
+	}
+	return new(big.Int).Mul(d.value(), pow10(scale-d.scale))
+}
+
+func maxScale(d0 Decimal, d1 Decimal) int32 {
+	if d0.scale > d1.scale {
+		return d0.scale
+	}
+	return d1.scale
+}
+
+// the scale of the result is the biggest of the two
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := maxScale(d, other)
+	return Decimal{unscaled: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// the scale of the result is the biggest of the two
+func (d Decimal) Sub(other Decimal) Decimal {
+	scale := maxScale(d, other)
+	return Decimal{unscaled: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// the scale of the result is the sum of the two
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.value(), other.value()), scale: d.scale + other.scale}
+}
+
+// Quo give d / other rounded to scale fractional digits, false when other is zero.
+func (d Decimal) Quo(other Decimal, scale int32, mode RoundingMode) (Decimal, bool) {
+	if other.Sign() == 0 {
+		return Decimal{}, false
+	}
+	// d / other = (a / 10^sa) / (b / 10^sb) = a * 10^sb / (b * 10^sa)
+	numerator := new(big.Int).Mul(d.value(), pow10(other.scale+scale))
+	denominator := new(big.Int).Mul(other.value(), pow10(d.scale))
+	return Decimal{unscaled: roundQuotient(numerator, denominator, mode), scale: scale}, true
+}
+
+// QuoRem give the quotient truncated toward zero and the remainder (with the sign of d) like
+// the Go operators on integers, false when other is zero.
+func (d Decimal) QuoRem(other Decimal) (Decimal, Decimal, bool) {
+	quotient, ok := d.Quo(other, 0, RoundDown)
+	if !ok {
+		return Decimal{}, Decimal{}, false
+	}
+	return quotient, d.Sub(other.Mul(quotient)), true
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	scale := maxScale(d, other)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+// the number of fractional digits
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Round give the value with exactly places fractional digits.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return Decimal{unscaled: d.rescale(places), scale: places}
+	}
+	return Decimal{unscaled: roundQuotient(d.value(), pow10(d.scale-places), mode), scale: places}
+}
+
+// the integer part (truncated toward zero), false when it does not fit in an int64
+func (d Decimal) Int64() (int64, bool) {
+	res := new(big.Int).Quo(d.value(), pow10(d.scale))
+	return res.Int64(), res.IsInt64()
+}
+
+func (d Decimal) Float64() float64 {
+	res, _ := new(big.Rat).SetFrac(d.value(), pow10(d.scale)).Float64()
+	return res
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+	if d.scale > 0 {
+		if missing := int(d.scale) + 1 - len(digits); missing > 0 {
+			digits = strings.Repeat("0", missing) + digits
+		}
+		cut := len(digits) - int(d.scale)
+		digits = digits[:cut] + "." + digits[cut:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+func (d Decimal) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, d.String())
+	return int64(n), err
+}
+
+func (d Decimal) Eval(env Environment) Object {
+	return d
+}
+
+// RoundingMode indicate how the discarded digits are handled.
+type RoundingMode int
+
+const (
+	// to the nearest, a tie goes away from zero (2.5 give 3, -2.5 give -3).
+	RoundHalfUp RoundingMode = iota
+	// to the nearest, a tie goes to the even neighbour (2.5 give 2, 3.5 give 4).
+	RoundHalfEven
+	// to the nearest, a tie goes toward zero (2.5 give 2).
+	RoundHalfDown
+	// away from zero.
+	RoundUp
+	// toward zero (truncation).
+	RoundDown
+	// toward positive infinity.
+	RoundCeiling
+	// toward negative infinity.
+	RoundFloor
+)
+
+var roundingModeNames = map[string]RoundingMode{
+	"half-up": RoundHalfUp, "half-even": RoundHalfEven, "half-down": RoundHalfDown,
+	"up": RoundUp, "down": RoundDown, "ceiling": RoundCeiling, "floor": RoundFloor,
+}
+
+// ParseRoundingMode accept "half-up", "half-even", "half-down", "up", "down", "ceiling" and "floor".
+func ParseRoundingMode(name string) (RoundingMode, bool) {
+	mode, ok := roundingModeNames[name]
+	return mode, ok
+}
+
+// numerator / denominator rounded to an integer
+func roundQuotient(numerator *big.Int, denominator *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	sign := numerator.Sign() * denominator.Sign()
+	// compare the remainder with the half of the denominator
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+	halfCmp := half.Cmp(new(big.Int).Abs(denominator))
+	var away bool
+	switch mode {
+	case RoundHalfUp:
+		away = halfCmp >= 0
+	case RoundHalfEven:
+		away = halfCmp > 0 || (halfCmp == 0 && quotient.Bit(0) == 1)
+	case RoundHalfDown:
+		away = halfCmp > 0
+	case RoundUp:
+		away = true
+	case RoundCeiling:
+		away = sign > 0
+	case RoundFloor:
+		away = sign < 0
+	}
+	if away {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+	return quotient
+}
+
+// DecimalConvertible is the hook for the Go types which give a Decimal (see FromGo).
+type DecimalConvertible interface {
+	Decimal() Decimal
+}
+
+var decimalConvertibleType = reflect.TypeOf((*DecimalConvertible)(nil)).Elem()
+
+// the types whose String method give a decimal representation
+var decimalTypes sync.Map
+
+// RegisterDecimalType make FromGo convert the values of the type of sample (like a third party decimal type)
+// to Decimal by parsing the result of their String method.
+func RegisterDecimalType(sample fmt.Stringer) {
+	decimalTypes.Store(reflect.TypeOf(sample), struct{}{})
+}
+
+// convert the value when its type use one of the decimal hooks
+func toDecimal(value reflect.Value) (Decimal, bool) {
+	if !value.CanInterface() {
+		return Decimal{}, false
+	}
+	switch casted := value.Interface().(type) {
+	case DecimalConvertible:
+		return casted.Decimal(), true
+	case fmt.Stringer:
+		if _, ok := decimalTypes.Load(value.Type()); ok {
+			return ParseDecimal(casted.String())
+		}
+	}
+	return Decimal{}, false
+}
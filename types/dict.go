@@ -0,0 +1,154 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import "io"
+
+// Dict keep its keys (String or Integer) in insertion order.
+type Dict struct {
+	keys   []Object
+	values map[Object]Object
+	// contains an element built at import (see Share)
+	shared bool
+}
+
+func NewDict() *Dict {
+	return &Dict{values: map[Object]Object{}}
+}
+
+// only String and Integer are valid keys
+func validKey(key Object) bool {
+	switch key.(type) {
+	case String, Integer:
+		return true
+	}
+	return false
+}
+
+func (d *Dict) WriteTo(w io.Writer) (int64, error) {
+	return 0, nil
+}
+
+func (d *Dict) Eval(env Environment) Object {
+	return d
+}
+
+func (d *Dict) Get(key Object) (Object, bool) {
+	if !validKey(key) {
+		return None, false
+	}
+	res, ok := d.values[key]
+	if !ok {
+		return None, false
+	}
+	return res, true
+}
+
+func (d *Dict) Has(key Object) bool {
+	_, ok := d.Get(key)
+	return ok
+}
+
+func (d *Dict) Load(key Object) Object {
+	res, _ := d.Get(key)
+	return res
+}
+
+func (d *Dict) LoadStr(key string) (Object, bool) {
+	return d.Get(String(key))
+}
+
+// an existing key keep its place, an invalid key is ignored.
+func (d *Dict) Store(key Object, value Object) {
+	if !validKey(key) {
+		return
+	}
+	if _, ok := d.values[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+	d.values[key] = value
+}
+
+func (d *Dict) StoreStr(key string, value Object) {
+	d.Store(String(key), value)
+}
+
+func (d *Dict) Delete(key Object) {
+	if !d.Has(key) {
+		return
+	}
+	delete(d.values, key)
+	for index, current := range d.keys {
+		if current == key {
+			d.keys = append(d.keys[:index:index], d.keys[index+1:]...)
+			break
+		}
+	}
+}
+
+func (d *Dict) Size() int {
+	return len(d.keys)
+}
+
+// the keys in insertion order.
+func (d *Dict) Keys() []Object {
+	return append([]Object(nil), d.keys...)
+}
+
+// give (key value) pairs in insertion order.
+func (d *Dict) Iter() Iterator {
+	return &dictIterator{dict: d, keys: d.keys}
+}
+
+// Copy give a shallow copy.
+func (d *Dict) Copy() *Dict {
+	res := &Dict{keys: d.Keys(), values: make(map[Object]Object, len(d.values))}
+	for key, value := range d.values {
+		res.values[key] = value
+	}
+	return res
+}
+
+// the keys are read at creation, a deleted key is skipped
+type dictIterator struct {
+	NoneType
+	dict    *Dict
+	keys    []Object
+	current int
+}
+
+func (it *dictIterator) Iter() Iterator {
+	return it
+}
+
+func (it *dictIterator) Next() (Object, bool) {
+	for it.current < len(it.keys) {
+		key := it.keys[it.current]
+		it.current++
+		if value, ok := it.dict.Get(key); ok {
+			return NewList(key, value), true
+		}
+	}
+	return None, false
+}
+
+func (it *dictIterator) Close() {
+	it.dict = nil
+	it.keys = nil
+}
@@ -0,0 +1,268 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"io"
+	"strings"
+)
+
+// CloseMode indicate how an element is closed.
+type CloseMode int
+
+const (
+	// self-closed when there is no children (like in XML).
+	XmlClose CloseMode = iota
+	// always an explicit closing tag.
+	HtmlClose
+	// no closing tag and the children are ignored (like br in HTML).
+	VoidClose
+)
+
+type Attribute struct {
+	Name  string
+	Value Object
+}
+
+// An attribute with the value None or false is not written, with the value true it is written bare.
+type Attributes []Attribute
+
+func (a Attributes) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, attr := range a {
+		var values []Object
+		switch casted := attr.Value.(type) {
+		case NoneType:
+			// attribute dropped
+		case Boolean:
+			if casted {
+				values = []Object{String(" " + attr.Name)}
+			}
+		default:
+			values = []Object{String(" " + attr.Name + "=\""), casted, String("\"")}
+		}
+		for _, value := range values {
+			n2, err := value.WriteTo(w)
+			n += n2
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Text give the text of the object as written in the output (an attribute value or a child),
+// empty for None and Boolean.
+func Text(object Object) string {
+	switch object.(type) {
+	case NoneType, Boolean:
+		return ""
+	}
+	var builder strings.Builder
+	object.WriteTo(&builder)
+	return builder.String()
+}
+
+func (a Attributes) Eval(env Environment) Object {
+	return a
+}
+
+// The output of tags, serialized only when written.
+// From a template, (. element Name), (. element Attributes) and (. element Children) give the fields
+// and ([] element "name") the value of an attribute (None if missing), ([]= element "name" value) change it
+// (an execution use its own copy of the elements built at import, see Share).
+type Element struct {
+	Name       string
+	Attributes Attributes
+	Children   *List
+	Close      CloseMode
+	// position of the tag call in the template source, when known
+	Position Position
+	// built at import and used by all the executions (see Share)
+	shared bool
+}
+
+func NewElement(name string, close CloseMode) *Element {
+	return &Element{Name: name, Children: NewList(), Close: close}
+}
+
+func (e *Element) Attr(name string) (Object, bool) {
+	for _, attr := range e.Attributes {
+		if attr.Name == name {
+			return attr.Value, true
+		}
+	}
+	return None, false
+}
+
+// change the value of the attribute or add it at the end.
+func (e *Element) SetAttr(name string, value Object) {
+	for index, attr := range e.Attributes {
+		if attr.Name == name {
+			e.Attributes[index].Value = value
+			return
+		}
+	}
+	e.Attributes = append(e.Attributes, Attribute{Name: name, Value: value})
+}
+
+func (e *Element) DeleteAttr(name string) {
+	for index, attr := range e.Attributes {
+		if attr.Name == name {
+			e.Attributes = append(e.Attributes[:index], e.Attributes[index+1:]...)
+			return
+		}
+	}
+}
+
+func (e *Element) LoadStr(key string) (Object, bool) {
+	switch key {
+	case "Name":
+		return String(e.Name), true
+	case "Attributes":
+		res := &List{categories: map[string]NoneType{}, inner: make([]Object, 0, len(e.Attributes))}
+		for _, attr := range e.Attributes {
+			res.Add(NewList(String(attr.Name), attr.Value))
+		}
+		return res, true
+	case "Children":
+		return e.Children, true
+	}
+	return None, false
+}
+
+func (e *Element) Load(key Object) Object {
+	str, ok := key.(String)
+	if !ok {
+		return None
+	}
+	res, _ := e.Attr(string(str))
+	return res
+}
+
+func (e *Element) Store(key Object, value Object) {
+	str, ok := key.(String)
+	if ok {
+		e.SetAttr(string(str), value)
+	}
+}
+
+func (e *Element) WriteTo(w io.Writer) (int64, error) {
+	values := []Object{String("<" + e.Name), e.Attributes}
+	switch {
+	case e.Close == VoidClose:
+		values = append(values, String(">"))
+	case e.Close == XmlClose && e.Children.Size() == 0:
+		values = append(values, String("/>"))
+	default:
+		values = append(values, String(">"), e.Children, String("</"+e.Name+">"))
+	}
+
+	var n int64
+	for _, value := range values {
+		n2, err := value.WriteTo(w)
+		n += n2
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (e *Element) Eval(env Environment) Object {
+	return e
+}
+
+// Walk call visit on each element reachable from object (through List and children),
+// when visit return false the children of the element are skipped.
+func Walk(object Object, visit func(*Element) bool) {
+	switch casted := object.(type) {
+	case *Element:
+		if visit(casted) && casted.Close != VoidClose {
+			Walk(casted.Children, visit)
+		}
+	case *List:
+		for _, value := range casted.inner {
+			Walk(value, visit)
+		}
+	}
+}
+
+// Share mark the elements reachable from object (through lists, Dicts and children) as shared between
+// the executions, like the lists and Dicts containing them, and report whether there is one (see IsShared).
+func Share(object Object) bool {
+	switch casted := object.(type) {
+	case *Element:
+		casted.shared = true
+		Share(casted.Children)
+		return true
+	case *List:
+		for _, value := range casted.inner {
+			// no short-circuit, all the elements are marked
+			casted.shared = Share(value) || casted.shared
+		}
+		return casted.shared
+	case *Dict:
+		for _, value := range casted.values {
+			casted.shared = Share(value) || casted.shared
+		}
+		return casted.shared
+	}
+	return false
+}
+
+// IsShared report whether object is a shared element, or a list or a Dict containing one
+// (they are copied with CopyTree before an execution use them).
+func IsShared(object Object) bool {
+	switch casted := object.(type) {
+	case *Element:
+		return casted.shared
+	case *List:
+		return casted.shared
+	case *Dict:
+		return casted.shared
+	}
+	return false
+}
+
+// CopyTree copy the elements, lists and Dicts reachable from object (the copies are not shared),
+// other objects are shared.
+func CopyTree(object Object) Object {
+	switch casted := object.(type) {
+	case *Element:
+		res := &Element{Name: casted.Name, Close: casted.Close, Position: casted.Position}
+		res.Attributes = append(Attributes(nil), casted.Attributes...)
+		res.Children = CopyTree(casted.Children).(*List)
+		return res
+	case *List:
+		res := &List{categories: casted.CopyCategories(), inner: make([]Object, 0, len(casted.inner))}
+		for _, value := range casted.inner {
+			res.Add(CopyTree(value))
+		}
+		return res
+	case *Dict:
+		res := &Dict{keys: casted.Keys(), values: make(map[Object]Object, len(casted.values))}
+		for key, value := range casted.values {
+			res.values[key] = CopyTree(value)
+		}
+		return res
+	}
+	return object
+}
@@ -20,7 +20,7 @@ package types
 
 import (
 	"reflect"
-	"sync"
+	"sort"
 )
 
 type BaseEnvironment struct {
@@ -81,47 +81,42 @@ func (b BaseEnvironment) Size() int {
 	return len(b.objects)
 }
 
+// give (key value) pairs in the order of the sorted keys, the keys are read at creation.
 func (b BaseEnvironment) Iter() Iterator {
-	it := &chanIterator{channel: make(chan Object), done: make(chan NoneType)}
-	go it.sendMapValue(b.objects)
-	return it
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return &envIterator{objects: b.objects, keys: keys}
 }
 
-type chanIterator struct {
+// a deleted key is skipped
+type envIterator struct {
 	NoneType
-	channel chan Object
-	done    chan NoneType
-	once    sync.Once
+	objects map[string]Object
+	keys    []string
+	current int
 }
 
-func (it *chanIterator) Iter() Iterator {
+func (it *envIterator) Iter() Iterator {
 	return it
 }
 
-func (it *chanIterator) Next() (Object, bool) {
-	value, ok := <-it.channel
-	if !ok {
-		return None, false
+func (it *envIterator) Next() (Object, bool) {
+	for it.current < len(it.keys) {
+		key := it.keys[it.current]
+		it.current++
+		if value, ok := it.objects[key]; ok {
+			return NewList(String(key), value), true
+		}
 	}
-	return value, true
-}
-
-func (it *chanIterator) Close() {
-	it.once.Do(func() {
-		close(it.done)
-	})
+	return None, false
 }
 
-func (it *chanIterator) sendMapValue(objects map[string]Object) {
-ForLoop:
-	for key, value := range objects {
-		select {
-		case it.channel <- NewList(String(key), value):
-		case <-it.done:
-			break ForLoop
-		}
-	}
-	close(it.channel)
+func (it *envIterator) Close() {
+	it.objects = nil
+	it.keys = nil
 }
 
 func MakeBaseEnvironment() BaseEnvironment {
@@ -172,9 +167,9 @@ func MakeDataEnvironment(data any, env Environment) DataEnvironment {
 	if !isNil {
 		switch dataValue.Kind() {
 		case reflect.Struct:
-			loadConfirm = loadFromStruct(dataValue)
+			loadConfirm = defaultConverter.loadFromStruct(dataValue)
 		case reflect.Map:
-			loadConfirm = loadFromMap(dataValue)
+			loadConfirm = defaultConverter.loadFromMap(dataValue)
 		}
 	}
 	return DataEnvironment{loadData: loadConfirm, Environment: env}
@@ -0,0 +1,124 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var objectType = reflect.TypeOf((*Object)(nil)).Elem()
+
+// ErrNotConvertible is wrapped by the errors of argument conversion.
+var ErrNotConvertible = errors.New("not convertible")
+
+// CallError is raised (with panic) when a wrapped Go function can not be called with its arguments
+// or return a non nil error, the template execution recover it and return it.
+type CallError struct {
+	// the name of the function, when known
+	Name string
+	Err  error
+}
+
+func (e CallError) Error() string {
+	if e.Name == "" {
+		return "call failed : " + e.Err.Error()
+	}
+	return "call of " + e.Name + " failed : " + e.Err.Error()
+}
+
+func (e CallError) Unwrap() error {
+	return e.Err
+}
+
+// WrapFunc give an Appliable calling fn which must be a Go function (see CallError),
+// the arguments are evaluated and converted to the parameter types of fn (a variadic function
+// accept any number of trailing arguments), the results are converted back (None when there is none,
+// a List when there is several) and a last error result is not part of the returned value.
+func WrapFunc(name string, fn any) (NativeAppliable, error) {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func || fnValue.IsNil() {
+		return NativeAppliable{}, fmt.Errorf("%s is not a function (%T)", name, fn)
+	}
+	return funcToAppliable(name, fnValue), nil
+}
+
+func funcToAppliable(name string, fn reflect.Value) NativeAppliable {
+	return MakeNativeAppliable(func(env Environment, itArgs Iterator) Object {
+		var args []Object
+		ForEach(itArgs, func(arg Object) bool {
+			args = append(args, arg.Eval(env))
+			return true
+		})
+		res, err := callFunc(fn, args)
+		if err != nil {
+			panic(CallError{Name: name, Err: err})
+		}
+		return res
+	})
+}
+
+func callFunc(fn reflect.Value, args []Object) (Object, error) {
+	fnType := fn.Type()
+	numIn := fnType.NumIn()
+	variadic := fnType.IsVariadic()
+	if size := len(args); size != numIn && !(variadic && size >= numIn-1) {
+		if variadic {
+			return None, fmt.Errorf("wait at least %d arguments, get %d", numIn-1, size)
+		}
+		return None, fmt.Errorf("wait %d arguments, get %d", numIn, size)
+	}
+
+	values := make([]reflect.Value, 0, len(args))
+	for index, arg := range args {
+		var paramType reflect.Type
+		if variadic && index >= numIn-1 {
+			paramType = fnType.In(numIn - 1).Elem()
+		} else {
+			paramType = fnType.In(index)
+		}
+		value, err := toValue(arg, paramType)
+		if err != nil {
+			return None, fmt.Errorf("argument %d : %w", index+1, err)
+		}
+		values = append(values, value)
+	}
+
+	results := fn.Call(values)
+	if last := len(results) - 1; last >= 0 && fnType.Out(last) == errorType {
+		if err, _ := results[last].Interface().(error); err != nil {
+			return None, err
+		}
+		results = results[:last]
+	}
+
+	switch len(results) {
+	case 0:
+		return None, nil
+	case 1:
+		return valueToObject(results[0]), nil
+	}
+	res := &List{categories: map[string]NoneType{}, inner: make([]Object, 0, len(results))}
+	for _, result := range results {
+		res.Add(valueToObject(result))
+	}
+	return res, nil
+}
@@ -29,6 +29,10 @@ type Storable interface {
 	Store(Object, Object)
 }
 
+type Deletable interface {
+	Delete(Object)
+}
+
 type StringLoadable interface {
 	LoadStr(string) (Object, bool)
 }
@@ -68,3 +72,7 @@ type Appliable interface {
 	Apply(Environment, Iterable) Object
 	ApplyWithData(any, Environment, Iterable) Object
 }
+
+type Positioned interface {
+	Position() Position
+}
@@ -23,6 +23,18 @@ import "io"
 type List struct {
 	categories map[string]NoneType
 	inner      []Object
+	position   Position
+	// contains an element built at import (see Share)
+	shared bool
+}
+
+// position in the parsed source, the zero value when unknown.
+func (l *List) Position() Position {
+	return l.position
+}
+
+func (l *List) SetPosition(position Position) {
+	l.position = position
 }
 
 func (l *List) AddCategory(category string) {
@@ -151,6 +163,11 @@ func (it *listIterator) Next() (Object, bool) {
 func (it *listIterator) Close() {
 }
 
+// allow a NativeAppliable to know the position of its call.
+func (it *listIterator) Position() Position {
+	return it.list.position
+}
+
 func (l *List) Iter() Iterator {
 	return &listIterator{list: l}
 }
@@ -179,7 +196,7 @@ func (l *List) Eval(env Environment) Object {
 	if appliable, ok := value0.(Appliable); ok {
 		return appliable.Apply(env, it)
 	}
-	l2 := &List{categories: l.CopyCategories(), inner: make([]Object, 0, len(l.inner))}
+	l2 := &List{categories: l.CopyCategories(), inner: make([]Object, 0, len(l.inner)), position: l.position}
 	l2.Add(value0)
 	for _, value := range l.inner[1:] {
 		l2.Add(value.Eval(env))
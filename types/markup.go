@@ -0,0 +1,73 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import "io"
+
+// MarkupKind identify the constructs of the output which are not elements.
+type MarkupKind int
+
+const (
+	// <!-- text -->
+	CommentMarkup MarkupKind = iota
+	// <![CDATA[text]]>
+	CDataMarkup
+	// <?text attributes?>
+	ProcessingMarkup
+	// <!DOCTYPE text>
+	DoctypeMarkup
+)
+
+// The output of Comment, CData, ProcessingInstruction (and Prologue) and Doctype,
+// the text is written as is (the builtins give a valid one).
+type Markup struct {
+	Kind MarkupKind
+	// the comment, the CDATA content, the target of the processing instruction or the doctype
+	Text string
+	// only used by a processing instruction
+	Attributes Attributes
+}
+
+func (m Markup) WriteTo(w io.Writer) (int64, error) {
+	var values []Object
+	switch m.Kind {
+	case CommentMarkup:
+		values = []Object{String("<!-- " + m.Text + " -->")}
+	case CDataMarkup:
+		values = []Object{String("<![CDATA[" + m.Text + "]]>")}
+	case ProcessingMarkup:
+		values = []Object{String("<?" + m.Text), m.Attributes, String("?>")}
+	case DoctypeMarkup:
+		values = []Object{String("<!DOCTYPE " + m.Text + ">")}
+	}
+
+	var n int64
+	for _, value := range values {
+		n2, err := value.WriteTo(w)
+		n += n2
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (m Markup) Eval(env Environment) Object {
+	return m
+}
@@ -0,0 +1,87 @@
+/*
+ *
+ * Copyright 2022 indentlang authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"io"
+	"reflect"
+	"time"
+)
+
+// Time is written with the RFC 3339 layout, (. t Year) give a part of it.
+type Time time.Time
+
+func (t Time) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, time.Time(t).Format(time.RFC3339))
+	return int64(n), err
+}
+
+func (t Time) Eval(env Environment) Object {
+	return t
+}
+
+// the parts are Year, Month, Day, Hour, Minute, Second, Nanosecond, Weekday, YearDay, Unix and Zone.
+func (t Time) LoadStr(key string) (Object, bool) {
+	inner := time.Time(t)
+	switch key {
+	case "Year":
+		return Integer(inner.Year()), true
+	case "Month":
+		return Integer(inner.Month()), true
+	case "Day":
+		return Integer(inner.Day()), true
+	case "Hour":
+		return Integer(inner.Hour()), true
+	case "Minute":
+		return Integer(inner.Minute()), true
+	case "Second":
+		return Integer(inner.Second()), true
+	case "Nanosecond":
+		return Integer(inner.Nanosecond()), true
+	case "Weekday":
+		return Integer(inner.Weekday()), true
+	case "YearDay":
+		return Integer(inner.YearDay()), true
+	case "Unix":
+		return Integer(inner.Unix()), true
+	case "Zone":
+		name, _ := inner.Zone()
+		return String(name), true
+	}
+	return None, false
+}
+
+func (t Time) Load(key Object) Object {
+	return Load(t, key)
+}
+
+// Duration is written like "1h30m0s".
+type Duration time.Duration
+
+func (d Duration) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, time.Duration(d).String())
+	return int64(n), err
+}
+
+func (d Duration) Eval(env Environment) Object {
+	return d
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
@@ -21,6 +21,7 @@ package types
 import (
 	"fmt"
 	"io"
+	"strconv"
 )
 
 type NoneType struct{}
@@ -123,6 +124,19 @@ func (i Identifier) Eval(env Environment) Object {
 	return value
 }
 
+// Line start at 1, the zero value is an unknown position.
+type Position struct {
+	Source string `json:"source"`
+	Line   int    `json:"line,omitempty"`
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.Source
+	}
+	return p.Source + ":" + strconv.Itoa(p.Line)
+}
+
 type NativeAppliable struct {
 	NoneType
 	inner func(Environment, Iterator) Object
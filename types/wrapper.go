@@ -19,15 +19,19 @@
 package types
 
 import (
+	"encoding"
+	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type ConvertString func(string) (Object, bool)
 type ExtractIterator func() Iterator
 
-var stringType = reflect.TypeOf("")
-
 func indirect(value reflect.Value) (reflect.Value, bool) {
 	isNil := false
 	for ; value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface; value = value.Elem() {
@@ -42,22 +46,131 @@ func neverConfirm(s string) (Object, bool) {
 	return None, false
 }
 
-func loadFromStruct(value reflect.Value) ConvertString {
+// the tag which rename a field ("-" hide it)
+const tagName = "il"
+
+type structField struct {
+	name  string
+	index []int
+}
+
+// cache of the visible fields by struct type
+var structFieldsCache sync.Map
+
+// exported fields with the Go promotion rules, renamed by their il tag,
+// embedded structs are only reachable by their promoted fields unless tagged
+func structFields(structType reflect.Type) []structField {
+	if cached, ok := structFieldsCache.Load(structType); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.IsExported() {
+			continue
+		}
+		tag, tagged := field.Tag.Lookup(tagName)
+		if tag == "-" || (field.Anonymous && !tagged && indirectType(field.Type).Kind() == reflect.Struct) {
+			continue
+		}
+		name := field.Name
+		if tag != "" {
+			name = tag
+		}
+		fields = append(fields, structField{name: name, index: field.Index})
+	}
+	// a tag can reuse the name of another field, the tagged one wins
+	res := make([]structField, 0, len(fields))
+	for _, field := range fields {
+		if index := indexField(res, field.name); index == -1 {
+			res = append(res, field)
+		} else if tagged(structType, field) {
+			res[index] = field
+		}
+	}
+	structFieldsCache.Store(structType, res)
+	return res
+}
+
+func indirectType(fieldType reflect.Type) reflect.Type {
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType
+}
+
+func indexField(fields []structField, name string) int {
+	for index, field := range fields {
+		if field.name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+func tagged(structType reflect.Type, field structField) bool {
+	_, ok := structType.FieldByIndex(field.index).Tag.Lookup(tagName)
+	return ok
+}
+
+// a field inside a nil embedded pointer is None
+func (c converter) fieldValue(value reflect.Value, field structField) Object {
+	fieldValue, err := value.FieldByIndexErr(field.index)
+	if err != nil {
+		return None
+	}
+	if fieldValue.Kind() == reflect.Func && fieldValue.CanInterface() && !fieldValue.IsNil() {
+		// keep the name for the errors
+		return funcToAppliable(field.name, fieldValue)
+	}
+	return c.toObject(fieldValue)
+}
+
+// the fields are searched before the exported methods (value and pointer receivers)
+func (c converter) loadFromStruct(value reflect.Value) ConvertString {
 	return func(fieldName string) (Object, bool) {
-		field := value.FieldByName(fieldName)
-		if !field.IsValid() {
-			return None, false
+		for _, field := range structFields(value.Type()) {
+			if field.name == fieldName {
+				return c.fieldValue(value, field), true
+			}
+		}
+		if method := methodByName(value, fieldName); method.IsValid() {
+			return funcToAppliable(fieldName, method), true
 		}
-		return valueToObject(field), true
+		return None, false
 	}
 }
 
+func methodByName(value reflect.Value, name string) reflect.Value {
+	if !value.CanInterface() {
+		// obtained through an unexported field
+		return reflect.Value{}
+	}
+	valueType := value.Type()
+	if method, ok := valueType.MethodByName(name); ok {
+		return value.Method(method.Index)
+	}
+	method, ok := reflect.PointerTo(valueType).MethodByName(name)
+	if !ok {
+		return reflect.Value{}
+	}
+	if value.CanAddr() {
+		value = value.Addr()
+	} else {
+		// work on a copy to allow pointer receivers
+		copied := reflect.New(valueType)
+		copied.Elem().Set(value)
+		value = copied
+	}
+	return value.Method(method.Index)
+}
+
 type structIterator struct {
 	NoneType
+	converter   converter
 	innerStruct reflect.Value
-	innerType   reflect.Type
-	current     int64
-	end         int64
+	fields      []structField
+	current     int
 }
 
 func (it *structIterator) Iter() Iterator {
@@ -66,12 +179,10 @@ func (it *structIterator) Iter() Iterator {
 
 func (it *structIterator) Next() (Object, bool) {
 	var res Object = None
-	ok := it.current < it.end
+	ok := it.current < len(it.fields)
 	if ok {
-		current := int(it.current)
-		name := it.innerType.Field(current).Name
-		field := it.innerStruct.Field(current)
-		res = NewList(String(name), valueToObject(field))
+		field := it.fields[it.current]
+		res = NewList(String(field.name), it.converter.fieldValue(it.innerStruct, field))
 		it.current++
 	}
 	return res, ok
@@ -79,67 +190,164 @@ func (it *structIterator) Next() (Object, bool) {
 
 func (it *structIterator) Close() {
 	it.innerStruct = reflect.Value{}
-	it.innerType = nil
+	it.fields = nil
 }
 
-func iterFromStruct(value reflect.Value) ExtractIterator {
+func (c converter) iterFromStruct(value reflect.Value) ExtractIterator {
 	return func() Iterator {
-		return &structIterator{innerStruct: value, innerType: value.Type(), end: int64(value.NumField())}
+		return &structIterator{converter: c, innerStruct: value, fields: structFields(value.Type())}
 	}
 }
 
-func loadFromMap(value reflect.Value) ConvertString {
-	if !stringType.AssignableTo(value.Type().Key()) {
-		return neverConfirm
+// the string is converted to the key type (see mapKey)
+func (c converter) loadFromMap(value reflect.Value) ConvertString {
+	keyType := value.Type().Key()
+	if !plainStringKey(keyType) {
+		loadKey := c.loadKeyFromMap(value)
+		return func(fieldName string) (Object, bool) {
+			return loadKey(String(fieldName))
+		}
 	}
 	return func(fieldName string) (Object, bool) {
-		resValue := value.MapIndex(reflect.ValueOf(fieldName))
-		if !resValue.IsValid() {
+		return c.mapIndex(value, reflect.ValueOf(fieldName).Convert(keyType))
+	}
+}
+
+func (c converter) loadKeyFromMap(value reflect.Value) func(Object) (Object, bool) {
+	keyType := value.Type().Key()
+	plainString := plainStringKey(keyType)
+	return func(key Object) (Object, bool) {
+		if str, ok := key.(String); ok && plainString {
+			return c.mapIndex(value, reflect.ValueOf(string(str)).Convert(keyType))
+		}
+		keyValue, ok := mapKey(key, keyType)
+		if !ok {
 			return None, false
 		}
-		return valueToObject(resValue), true
+		return c.mapIndex(value, keyValue)
+	}
+}
+
+// a String is used as is for a string key without text to parse
+func plainStringKey(keyType reflect.Type) bool {
+	return keyType.Kind() == reflect.String && !reflect.PointerTo(keyType).Implements(textUnmarshalerType)
+}
+
+func (c converter) mapIndex(value reflect.Value, keyValue reflect.Value) (Object, bool) {
+	resValue := value.MapIndex(keyValue)
+	if !resValue.IsValid() {
+		return None, false
+	}
+	return c.toObject(resValue), true
+}
+
+// convert the key (with toValue, a String is also parsed when the key type implements
+// encoding.TextUnmarshaler), false when the conversion fails or the key is not comparable
+func mapKey(key Object, keyType reflect.Type) (reflect.Value, bool) {
+	keyValue, err := toValue(key, keyType)
+	if err != nil || !keyValue.Type().Comparable() {
+		return keyValue, false
+	}
+	if keyValue.Kind() == reflect.Interface && !keyValue.IsNil() && !keyValue.Elem().Type().Comparable() {
+		return keyValue, false
 	}
+	return keyValue, true
 }
 
-type mapIterWrapper struct {
+// iterate on the map in the order of its sorted keys
+type mapIterator struct {
 	NoneType
-	inner     *reflect.MapIter
-	exhausted bool
+	converter converter
+	inner     reflect.Value
+	keys      []reflect.Value
+	current   int
 }
 
-func (w *mapIterWrapper) Iter() Iterator {
-	return w
+func (it *mapIterator) Iter() Iterator {
+	return it
 }
 
-func (w *mapIterWrapper) Next() (Object, bool) {
-	if !w.exhausted {
-		if w.inner.Next() {
-			return NewList(valueToObject(w.inner.Key()), valueToObject(w.inner.Value())), true
-		} else {
-			w.exhausted = true
-		}
+func (it *mapIterator) Next() (Object, bool) {
+	if it.current >= len(it.keys) {
+		return None, false
 	}
-	return None, false
+	key := it.keys[it.current]
+	it.current++
+	return NewList(it.converter.keyToObject(key), it.converter.toObject(it.inner.MapIndex(key))), true
 }
 
-func (w *mapIterWrapper) Close() {
-	w.inner.Reset(reflect.Value{})
+func (it *mapIterator) Close() {
+	it.inner = reflect.Value{}
+	it.keys = nil
 }
 
-func iterFromMap(value reflect.Value) ExtractIterator {
+func (c converter) iterFromMap(value reflect.Value) ExtractIterator {
 	return func() Iterator {
-		return &mapIterWrapper{inner: value.MapRange()}
+		return &mapIterator{converter: c, inner: value, keys: sortValues(value.MapKeys())}
+	}
+}
+
+// a key implementing encoding.TextMarshaler give a String
+func (c converter) keyToObject(key reflect.Value) Object {
+	if !key.CanInterface() {
+		return c.toObject(key)
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return String(text)
+		}
+	}
+	return c.toObject(key)
+}
+
+// sort numbers, strings and booleans by value, the other values by their printed form
+func sortValues(values []reflect.Value) []reflect.Value {
+	sort.SliceStable(values, func(i, j int) bool {
+		return lessValue(values[i], values[j])
+	})
+	return values
+}
+
+func lessValue(value0 reflect.Value, value1 reflect.Value) bool {
+	for value0.Kind() == reflect.Interface && !value0.IsNil() {
+		value0 = value0.Elem()
+	}
+	for value1.Kind() == reflect.Interface && !value1.IsNil() {
+		value1 = value1.Elem()
 	}
+	if kind := value0.Kind(); kind == value1.Kind() {
+		switch kind {
+		case reflect.Bool:
+			return !value0.Bool() && value1.Bool()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return value0.Int() < value1.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return value0.Uint() < value1.Uint()
+		case reflect.Float32, reflect.Float64:
+			return value0.Float() < value1.Float()
+		case reflect.String:
+			return value0.String() < value1.String()
+		}
+	}
+	return fmt.Sprint(value0) < fmt.Sprint(value1)
 }
 
 type dataWrapper struct {
 	NoneType
+	// the wrapped struct or map (see ToGo)
+	inner    reflect.Value
 	loadData ConvertString
+	// nil when only String keys are allowed
+	loadKey  func(Object) (Object, bool)
 	iterData ExtractIterator
 }
 
 func (w dataWrapper) Load(key Object) Object {
-	return Load(w, key)
+	if w.loadKey == nil {
+		return Load(w, key)
+	}
+	res, _ := w.loadKey(key)
+	return res
 }
 
 func (w dataWrapper) LoadStr(s string) (Object, bool) {
@@ -150,9 +358,124 @@ func (w dataWrapper) Iter() Iterator {
 	return w.iterData()
 }
 
+// FromGoOption change the conversion done by FromGo.
+type FromGoOption func(*converter)
+
+// Structs and maps are copied in Dicts instead of being wrapped (a wrapper read the Go value when used),
+// the Dicts can then be modified by the template. A value referencing itself is copied once, the cycle
+// is replaced by None.
+func Detach() FromGoOption {
+	return func(c *converter) {
+		c.detach = true
+	}
+}
+
+// Byte slices give a String instead of a List of Integer.
+func BytesAsString() FromGoOption {
+	return func(c *converter) {
+		c.bytesAsString = true
+	}
+}
+
+type converter struct {
+	detach        bool
+	bytesAsString bool
+	// the values in an eager conversion (slices, arrays and detached structs and maps), to cut the cycles
+	visiting map[visit]NoneType
+}
+
+// the type is needed because a struct and its first field share their address
+type visit struct {
+	pointer   uintptr
+	valueType reflect.Type
+}
+
+// enter return false when value is already in conversion (a cycle which is converted to None),
+// a value which can not be reached by a pointer can not be in a cycle.
+func (c *converter) enter(value reflect.Value) (visit, bool) {
+	var key visit
+	switch value.Kind() {
+	case reflect.Map, reflect.Slice:
+		key = visit{pointer: value.Pointer(), valueType: value.Type()}
+	default:
+		if value.CanAddr() {
+			key = visit{pointer: value.Addr().Pointer(), valueType: value.Type()}
+		}
+	}
+	if key.pointer == 0 {
+		return key, true
+	}
+	if c.visiting == nil {
+		c.visiting = map[visit]NoneType{}
+	}
+	if _, ok := c.visiting[key]; ok {
+		return key, false
+	}
+	c.visiting[key] = None
+	return key, true
+}
+
+func (c converter) leave(key visit) {
+	if key.pointer != 0 {
+		delete(c.visiting, key)
+	}
+}
+
+var defaultConverter = converter{}
+
+// FromGo convert a Go value to an Object : an Object is kept, nil give None, numbers give Integer or Float,
+// time.Time and time.Duration give Time and Duration, the decimal types give Decimal (see RegisterDecimalType),
+// slices and arrays give List, structs and maps give wrappers (see Detach) with the fields
+// (see the il tag) and methods usable by the template and functions give Appliable (see WrapFunc).
+func FromGo(value any, opts ...FromGoOption) Object {
+	var c converter
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c.toObject(reflect.ValueOf(value))
+}
+
 func valueToObject(value reflect.Value) Object {
+	return defaultConverter.toObject(value)
+}
+
+var byteSliceType = reflect.TypeOf([]byte(nil))
+
+func (c converter) toObject(value reflect.Value) Object {
+	for ; value.Kind() == reflect.Interface; value = value.Elem() {
+		if value.IsNil() {
+			return None
+		}
+	}
+	if !value.IsValid() {
+		return None
+	}
+	if value.Type().Implements(objectType) && value.CanInterface() {
+		switch value.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func:
+			if value.IsNil() {
+				return None
+			}
+		}
+		return value.Interface().(Object)
+	}
+
+	if decimal, ok := decimalHook(value); ok {
+		return decimal
+	}
 	value, isNil := indirect(value)
 	if !isNil {
+		if decimal, ok := decimalHook(value); ok {
+			return decimal
+		}
+		switch value.Type() {
+		case timeType:
+			if value.CanInterface() {
+				return Time(value.Interface().(time.Time))
+			}
+		case durationType:
+			return Duration(value.Int())
+		}
 		switch value.Kind() {
 		case reflect.Bool:
 			return Boolean(value.Bool())
@@ -167,17 +490,87 @@ func valueToObject(value reflect.Value) Object {
 		case reflect.String:
 			return String(value.String())
 		case reflect.Array, reflect.Slice:
+			if c.bytesAsString && value.Type().ConvertibleTo(byteSliceType) {
+				return String(value.Convert(byteSliceType).Bytes())
+			}
+			key, ok := c.enter(value)
+			if !ok {
+				return None
+			}
+			defer c.leave(key)
 			size := value.Len()
 			l := &List{categories: map[string]NoneType{}, inner: make([]Object, 0, size)}
 			for index := 0; index < size; index++ {
-				l.Add(valueToObject(value.Index(index)))
+				l.Add(c.toObject(value.Index(index)))
 			}
 			return l
 		case reflect.Struct:
-			return dataWrapper{loadData: loadFromStruct(value), iterData: iterFromStruct(value)}
+			if !c.detach {
+				c.visiting = nil // the wrapper convert lazily
+				return dataWrapper{inner: value, loadData: c.loadFromStruct(value), iterData: c.iterFromStruct(value)}
+			}
+			key, ok := c.enter(value)
+			if !ok {
+				return None
+			}
+			defer c.leave(key)
+			return copyPairs(dataWrapper{inner: value, loadData: c.loadFromStruct(value), iterData: c.iterFromStruct(value)})
 		case reflect.Map:
-			return dataWrapper{loadData: loadFromMap(value), iterData: iterFromMap(value)}
+			if !c.detach {
+				c.visiting = nil // the wrapper convert lazily
+				return dataWrapper{
+					inner: value, loadData: c.loadFromMap(value), loadKey: c.loadKeyFromMap(value), iterData: c.iterFromMap(value),
+				}
+			}
+			key, ok := c.enter(value)
+			if !ok {
+				return None
+			}
+			defer c.leave(key)
+			return copyPairs(dataWrapper{
+				inner: value, loadData: c.loadFromMap(value), loadKey: c.loadKeyFromMap(value), iterData: c.iterFromMap(value),
+			})
+		case reflect.Func:
+			if value.CanInterface() && !value.IsNil() {
+				return funcToAppliable("", value)
+			}
 		}
 	}
 	return None
 }
+
+// see DecimalConvertible and RegisterDecimalType
+func decimalHook(value reflect.Value) (Decimal, bool) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return Decimal{}, false
+		}
+	}
+	return toDecimal(value)
+}
+
+// the keys which are not Integer are converted to String
+func copyPairs(pairs Iterable) Object {
+	res := NewDict()
+	ForEach(pairs, func(pair Object) bool {
+		if casted, ok := pair.(*List); ok && len(casted.inner) == 2 {
+			key := casted.inner[0]
+			if _, ok := key.(Integer); !ok {
+				key = String(objectText(key))
+			}
+			res.Store(key, casted.inner[1])
+		}
+		return true
+	})
+	return res
+}
+
+func objectText(object Object) string {
+	if str, ok := object.(String); ok {
+		return string(str)
+	}
+	var builder strings.Builder
+	object.WriteTo(&builder)
+	return builder.String()
+}